@@ -0,0 +1,148 @@
+package ankety
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"talant/auth"
+	"talant/problem"
+	"talant/request"
+	"time"
+)
+
+const (
+	birthDateLayout = "2006-01-02"
+	// MinAge - минимальный возраст кандидата (с 14 лет можно работать с согласия родителей)
+	MinAge = 14
+	// AdultAge - возраст, с которого кандидат не считается несовершеннолетним
+	AdultAge = 18
+	maxAge   = 100
+)
+
+// GuardianConsent - согласие родителя или законного представителя несовершеннолетнего
+type GuardianConsent struct {
+	Name     string    `json:"name,omitempty"`
+	Relation string    `json:"relation,omitempty"`
+	Contact  string    `json:"contact,omitempty"`
+	GivenAt  time.Time `json:"given_at"`
+}
+
+// GuardianInput - поля запроса согласия законного представителя
+type GuardianInput struct {
+	GuardianName    string `json:"guardian_name" validate:"trim,required,max=100"`
+	Relation        string `json:"relation" validate:"trim,required,max=50"`
+	GuardianContact string `json:"guardian_contact" validate:"trim,required,max=254"`
+}
+
+// ageOn возвращает число полных лет на дату now
+func ageOn(birth, now time.Time) int {
+	age := now.Year() - birth.Year()
+	if now.Month() < birth.Month() || (now.Month() == birth.Month() && now.Day() < birth.Day()) {
+		age--
+	}
+	return age
+}
+
+// parseBirthDate проверяет дату рождения и возвращает возраст на дату now
+func parseBirthDate(s string, now time.Time) (int, error) {
+	birth, err := time.Parse(birthDateLayout, s)
+	if err != nil {
+		return 0, request.Invalid("birth_date", "must be in YYYY-MM-DD format")
+	}
+	if birth.After(now) {
+		return 0, request.Invalid("birth_date", "is in the future")
+	}
+	age := ageOn(birth, now)
+	if age < MinAge || age > maxAge {
+		return 0, request.Invalid("birth_date", fmt.Sprintf("age must be between %d and %d", MinAge, maxAge))
+	}
+	return age, nil
+}
+
+// refreshAge пересчитывает производные поля Age и Minor из BirthDate
+func refreshAge(a *Ankety) error {
+	age, err := parseBirthDate(a.BirthDate, time.Now())
+	if err != nil {
+		return err
+	}
+	a.Age = strconv.Itoa(age)
+	a.Minor = age < AdultAge
+	return nil
+}
+
+// migrateBirthDate строит приблизительную дату рождения по старому строковому возрасту
+func migrateBirthDate(a *Ankety) {
+	if a.BirthDate != "" {
+		return
+	}
+	age, err := strconv.Atoi(strings.TrimSpace(a.Age))
+	if err != nil || age <= 0 {
+		return
+	}
+	a.BirthDate = time.Now().AddDate(-age, 0, 0).Format(birthDateLayout)
+	a.BirthDateApprox = true
+}
+
+// GuardianConsentHandler записывает согласие законного представителя
+// несовершеннолетнего кандидата (POST /ankety/{id}/guardian-consent)
+func GuardianConsentHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	var in GuardianInput
+	if _, err := request.Decode(w, r, &in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if err := request.Validate(&in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	consent := GuardianConsent{
+		Name:     in.GuardianName,
+		Relation: in.Relation,
+		Contact:  in.GuardianContact,
+		GivenAt:  time.Now().UTC(),
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	anketyList, err := LoadUser()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading ankety")
+		return
+	}
+	anketyID := r.PathValue("id")
+	if status, code, msg := checkOwner(anketyList, anketyID, userID); status != http.StatusOK {
+		problem.Write(w, status, code, msg)
+		return
+	}
+	for i := range anketyList {
+		if anketyList[i].Id == anketyID {
+			if !anketyList[i].Minor {
+				problem.Write(w, http.StatusBadRequest, problem.CodeConsentNotNeeded, "Guardian consent is only needed for minors")
+				return
+			}
+			anketyList[i].Guardian = &consent
+			break
+		}
+	}
+	if err := SaveAnkety(anketyList); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(consent)
+}
@@ -0,0 +1,107 @@
+package ankety
+
+import (
+	"errors"
+	"talant/request"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse(birthDateLayout, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestAgeOn(t *testing.T) {
+	tests := []struct {
+		birth, now string
+		want       int
+	}{
+		{birth: "2008-03-15", now: "2026-03-15", want: 18}, // день рождения сегодня
+		{birth: "2008-03-16", now: "2026-03-15", want: 17}, // день рождения завтра
+		{birth: "2008-03-14", now: "2026-03-15", want: 18},
+		{birth: "2008-04-01", now: "2026-03-15", want: 17},
+		{birth: "2008-02-20", now: "2026-03-15", want: 18},
+		{birth: "2008-12-31", now: "2026-01-01", want: 17},
+		// Родившиеся 29 февраля в невисокосный год взрослеют 1 марта
+		{birth: "2008-02-29", now: "2026-02-28", want: 17},
+		{birth: "2008-02-29", now: "2026-03-01", want: 18},
+		{birth: "2008-02-29", now: "2028-02-29", want: 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.birth+" on "+tt.now, func(t *testing.T) {
+			if got := ageOn(date(tt.birth), date(tt.now)); got != tt.want {
+				t.Errorf("ageOn = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBirthDate(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		birth   string
+		want    int
+		wantErr string
+	}{
+		{name: "exactly MinAge", birth: "2012-03-15", want: MinAge},
+		{name: "MinAge tomorrow", birth: "2012-03-16", wantErr: "age must be between 14 and 100"},
+		{name: "adult", birth: "2008-03-15", want: AdultAge},
+		{name: "exactly maxAge", birth: "1926-03-15", want: maxAge},
+		{name: "older than maxAge", birth: "1925-03-15", wantErr: "age must be between 14 and 100"},
+		{name: "born today", birth: "2026-03-15", wantErr: "age must be between 14 and 100"},
+		{name: "future", birth: "2026-03-16", wantErr: "is in the future"},
+		{name: "wrong format", birth: "15.03.2010", wantErr: "must be in YYYY-MM-DD format"},
+		{name: "impossible date", birth: "2010-02-30", wantErr: "must be in YYYY-MM-DD format"},
+		{name: "empty", birth: "", wantErr: "must be in YYYY-MM-DD format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			age, err := parseBirthDate(tt.birth, now)
+			if tt.wantErr == "" {
+				if err != nil || age != tt.want {
+					t.Fatalf("parseBirthDate = %d, %v; want %d", age, err, tt.want)
+				}
+				return
+			}
+			var e *request.Error
+			if !errors.As(err, &e) || len(e.Fields) != 1 || e.Fields[0].Field != "birth_date" || e.Fields[0].Message != tt.wantErr {
+				t.Fatalf("parseBirthDate error = %v, want birth_date: %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRefreshAgeMinor(t *testing.T) {
+	// refreshAge считает возраст на сегодня, поэтому даты берутся на день от границы
+	now := time.Now()
+	tests := []struct {
+		name      string
+		birth     time.Time
+		wantMinor bool
+	}{
+		{name: "turns 18 tomorrow", birth: now.AddDate(-AdultAge, 0, 1), wantMinor: true},
+		{name: "turned 18 yesterday", birth: now.AddDate(-AdultAge, 0, -1), wantMinor: false},
+		{name: "turned 14 yesterday", birth: now.AddDate(-MinAge, 0, -1), wantMinor: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := Ankety{BirthDate: tt.birth.Format(birthDateLayout), Age: "99"}
+			if err := refreshAge(&a); err != nil {
+				t.Fatal(err)
+			}
+			if a.Minor != tt.wantMinor {
+				t.Errorf("Minor = %v, want %v (age %s)", a.Minor, tt.wantMinor, a.Age)
+			}
+		})
+	}
+
+	a := Ankety{BirthDate: now.AddDate(-MinAge, 0, 1).Format(birthDateLayout)}
+	if err := refreshAge(&a); err == nil {
+		t.Error("refreshAge accepted a candidate younger than MinAge")
+	}
+}
@@ -2,10 +2,20 @@ package ankety
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
+	"sync"
 	"talant/auth"
+	"talant/moderation"
+	"talant/problem"
+	"talant/request"
+	"talant/school"
+	"talant/storage"
+	"talant/trash"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -13,106 +23,270 @@ import (
 type Ankety struct {
 	Id     string `json:"id"`
 	UserId string `json:"user_id"`
-	Name   string `json:"name"`
-	Gender string `json:"gender"`
-	Age    string `json:"age"`
-	Job    string `json:"job"`
-	School string `json:"school"`
+	Name   string `json:"name" validate:"trim,required,max=100"`
+	Gender string `json:"gender" validate:"required,oneof=male female other"`
+	// Age и Minor вычисляются из BirthDate при загрузке, см. refreshAge
+	Age       string `json:"age"`
+	BirthDate string `json:"birth_date" validate:"trim,required,date"`
+	// BirthDateApprox - дата рождения восстановлена по старому полю age и неточна
+	BirthDateApprox bool   `json:"birth_date_approx,omitempty"`
+	Minor           bool   `json:"minor"`
+	Job             string `json:"job" validate:"trim,required,max=200"`
+	School          string `json:"school" validate:"trim,required,max=200"`
+	// SchoolID - заведение из справочника, к которому приведено поле School
+	SchoolID string `json:"school_id,omitempty"`
+
+	Education  []Education  `json:"education" validate:"max=20"`
+	Experience []Experience `json:"experience" validate:"max=30"`
+	Skills     []Skill      `json:"skills" validate:"max=50"`
+	Languages  []Language   `json:"languages" validate:"max=20"`
+	Links      Links        `json:"links" validate:"inline"`
+
+	Resume *storage.File `json:"resume,omitempty"`
+	Photo  *storage.File `json:"photo,omitempty"`
+
+	// Visibility - кому видна анкета, см. canView
+	Visibility string   `json:"visibility" validate:"oneof=public employers applied hidden"`
+	Contacts   Contacts `json:"contacts" validate:"inline"`
+	// ContactsSharedWith - ID работодателей, которым кандидат разрешил видеть контакты
+	ContactsSharedWith []string `json:"contacts_shared_with,omitempty"`
+
+	Guardian *GuardianConsent `json:"guardian_consent,omitempty"`
+
+	// SalaryExpectation - желаемая зарплата в рублях, 0 - не указана
+	SalaryExpectation int `json:"salary_expectation,omitempty" validate:"min=0,max=10000000"`
+	// Moderation - результат проверки текста; пока анкета не одобрена, ее видит только владелец
+	Moderation moderation.Status `json:"moderation"`
+	// Schema - версия формата записи, см. migrate
+	Schema int `json:"schema_version"`
+	// DeletedAt - время удаления; заполнено только у анкет в корзине
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// Input - поля анкеты в запросах создания и правки
+type Input struct {
+	Name      string `json:"name"`
+	Gender    string `json:"gender"`
+	BirthDate string `json:"birth_date"`
+	Job       string `json:"job"`
+	School    string `json:"school"`
+	SchoolID  string `json:"school_id"`
+
+	Education  []Education  `json:"education"`
+	Experience []Experience `json:"experience"`
+	Skills     []Skill      `json:"skills"`
+	Languages  []Language   `json:"languages"`
+
+	SalaryExpectation int    `json:"salary_expectation"`
+	Portfolio         string `json:"portfolio"`
+	GitHub            string `json:"github"`
+
+	Visibility string `json:"visibility"`
+	Email      string `json:"email"`
+	Phone      string `json:"phone"`
+	Telegram   string `json:"telegram"`
 }
 
 var anketybase string = "ankety.json"
 
+// mu сериализует цикл "прочитать - изменить - записать" над anketybase,
+// иначе параллельные запросы могут создать две анкеты одному пользователю
+var mu sync.Mutex
+
 func LoadUser() ([]Ankety, error) {
 	data, err := os.ReadFile(anketybase)
 	if err != nil {
+		if os.IsNotExist(err) {
+			return []Ankety{}, nil
+		}
 		fmt.Printf("Ошибка чтения файла %s: %v\n", anketybase, err)
 		return nil, err
 	}
+	if len(data) == 0 {
+		return []Ankety{}, nil
+	}
 	var ankety []Ankety
 	err = json.Unmarshal(data, &ankety)
 	if err != nil {
 		fmt.Printf("Ошибка разбора JSON из файла %s: %v\n", anketybase, err)
 		return nil, err
 	}
+	for i := range ankety {
+		migrate(&ankety[i])
+		// Возраст меняется со временем, поэтому пересчитывается при каждой загрузке
+		refreshAge(&ankety[i])
+	}
 	return ankety, nil
 }
 
+// ErrDuplicateAnkety - у пользователя несколько анкет. Так бывало, пока проверка в
+// CreateHandler не была атомарной. Какую из анкет оставить, решает администратор:
+// анкеты не удаляются и не сливаются автоматически.
+var ErrDuplicateAnkety = errors.New("user has more than one ankety")
+
+// ofUser возвращает индекс анкеты пользователя или -1, если анкеты нет
+func ofUser(anketyList []Ankety, userID string) (int, error) {
+	found := -1
+	var ids []string
+	for i, a := range anketyList {
+		if a.UserId == userID {
+			found = i
+			ids = append(ids, a.Id)
+		}
+	}
+	if len(ids) > 1 {
+		return -1, fmt.Errorf("%w: %s", ErrDuplicateAnkety, strings.Join(ids, ", "))
+	}
+	return found, nil
+}
+
+// CheckDuplicates возвращает ErrDuplicateAnkety со списком пользователей,
+// у которых несколько анкет. Вызывается при запуске сервера.
+func CheckDuplicates() error {
+	anketyList, err := LoadUser()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	seen := map[string]bool{}
+	for _, a := range anketyList {
+		if seen[a.UserId] {
+			continue
+		}
+		seen[a.UserId] = true
+		if _, err := ofUser(anketyList, a.UserId); err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", a.UserId, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func SaveAnkety(anketyList []Ankety) error {
+	data, err := json.MarshalIndent(anketyList, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования в JSON: %w", err)
+	}
+	err = os.WriteFile(anketybase, data, 0644)
+	if err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", anketybase, err)
+	}
+	return nil
+}
+
 func CreateHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
 		return
 	}
-	name := r.FormValue("name")
-	gender := r.FormValue("gender")
-	age := r.FormValue("age")
-	job := r.FormValue("job")
-	school := r.FormValue("school")
-	if name == "" || age == "" || job == "" || school == "" || gender == "" {
-		http.Error(w, "Missing fields", http.StatusBadRequest)
+	var in Input
+	sent, err := request.Decode(w, r, &in)
+	if err != nil {
+		request.WriteError(w, err)
 		return
 	}
 
 	// Требуется получить идентификатор зарегистрированного пользователя из токена
-	cookie, err := r.Cookie("auth_token")
+	userID, err := auth.UserIDFromRequest(r)
 	if err != nil {
-		http.Error(w, "Unauthorized: missing token", http.StatusUnauthorized)
-		return
-	}
-	userID, _, err := auth.ValidateJWT(cookie.Value)
-	if err != nil {
-		http.Error(w, "Unauthorized: invalid token", http.StatusUnauthorized)
+		auth.Error(w, err)
 		return
 	}
+
+	mu.Lock()
+	defer mu.Unlock()
 	anketyList, err := LoadUser()
 	if err != nil {
-		http.Error(w, "Error loading ankety", http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading ankety")
 		return
 	}
 	for _, a := range anketyList {
 		if a.UserId == userID {
-			http.Error(w, "Ankety already exists for this user", http.StatusBadRequest)
+			problem.Write(w, http.StatusConflict, problem.CodeAlreadyExists, "Ankety already exists for this user")
 			return
 		}
 	}
 	// Сохраняем отдельный id анкеты и привязываем к ней userID
 	ankety := Ankety{
-		Id:     uuid.New().String(),
-		UserId: userID,
-		Name:   name,
-		Gender: gender,
-		Age:    age,
-		Job:    job,
-		School: school,
+		Id:        uuid.New().String(),
+		UserId:    userID,
+		Name:      in.Name,
+		Gender:    in.Gender,
+		BirthDate: in.BirthDate,
+		Job:       in.Job,
+		School:    in.School,
+		Schema:    currentSchema,
+	}
+	parseProfile(in, sent, &ankety, true)
+	parsePrivacy(in, sent, &ankety, true)
+	if err := validateAnkety(&ankety); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if err := resolveSchool(in.SchoolID, &ankety); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if len(ankety.Education) == 0 {
+		ankety.Education = []Education{{School: ankety.School}}
+	}
+	ankety.Moderation, err = moderation.Submit(moderationContent(ankety))
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Moderation error")
+		return
 	}
 
 	anketyList = append(anketyList, ankety)
-	updatedData, err := json.MarshalIndent(anketyList, "", "  ")
-	if err != nil {
-		http.Error(w, "Error encoding data", http.StatusInternalServerError)
+	if err := SaveAnkety(anketyList); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
 		return
 	}
-	err = os.WriteFile(anketybase, updatedData, 0644)
+	recordRevision(ankety, 0)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ankety)
+}
+
+// validateAnkety проверяет анкету по правилам из тегов validate, см. request.Validate,
+// пересчитывает возраст и возвращает все нарушения сразу
+func validateAnkety(a *Ankety) error {
+	errs := []error{request.Validate(a), validateProfile(a)}
+	// Формат даты рождения проверяет правило date, здесь - возраст
+	if _, err := time.Parse(birthDateLayout, a.BirthDate); err == nil {
+		errs = append(errs, refreshAge(a))
+	}
+	return request.Join(errs...)
+}
+
+// resolveSchool приводит a.School к справочнику заведений, см. school.Resolve
+func resolveSchool(schoolID string, a *Ankety) error {
+	id, name, err := school.Resolve(schoolID, a.School)
+	if errors.Is(err, school.ErrUnknownSchool) {
+		return request.Invalid("school_id", err.Error())
+	}
 	if err != nil {
-		http.Error(w, "Error writing data file", http.StatusInternalServerError)
-		return
+		return fmt.Errorf("error loading schools: %w", err)
 	}
+	a.SchoolID, a.School = id, name
+	return nil
 }
 
 func ShowAnketyHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	anketyList, err := LoadUser()
+	// Показываем только те анкеты, которые разрешено видеть вызывающему
+	visible, err := VisibleTo(r)
 	if err != nil {
-		http.Error(w, "Error loading ankety", http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading ankety")
 		return
 	}
 
-	responseData, err := json.MarshalIndent(anketyList, "", "  ")
+	responseData, err := json.MarshalIndent(visible, "", "  ")
 	if err != nil {
-		http.Error(w, "Error encoding data", http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error encoding data")
 		return
 	}
 
@@ -120,3 +294,243 @@ func ShowAnketyHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write(responseData)
 }
+
+// MyHandler возвращает анкету текущего пользователя
+func MyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	anketyList, err := LoadUser()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading ankety")
+		return
+	}
+
+	i, err := ofUser(anketyList, userID)
+	if err != nil {
+		problem.Write(w, http.StatusConflict, problem.CodeConflict, err.Error())
+		return
+	}
+	if i < 0 {
+		problem.Write(w, http.StatusNotFound, problem.CodeAnketyNotFound, "Ankety not found")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(anketyList[i])
+}
+
+// OpenHandler возвращает анкету по ее ID (/ankety/{id})
+func OpenHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	anketyID := r.PathValue("id")
+	if anketyID == "" {
+		problem.Write(w, http.StatusBadRequest, problem.CodeMissingID, "Missing ankety ID")
+		return
+	}
+
+	anketyList, err := LoadUser()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading ankety")
+		return
+	}
+
+	v := viewerFrom(r)
+	for _, a := range anketyList {
+		if a.Id == anketyID && canView(a, v) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(present(a, v))
+			return
+		}
+	}
+	// Скрытая анкета неотличима от несуществующей
+	problem.Write(w, http.StatusNotFound, problem.CodeAnketyNotFound, "Ankety not found")
+}
+
+// UpdateHandler обновляет анкету владельца.
+// PUT заменяет все поля и требует их наличия, PATCH меняет только переданные поля.
+func UpdateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodPatch {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	anketyID := r.PathValue("id")
+	if anketyID == "" {
+		problem.Write(w, http.StatusBadRequest, problem.CodeMissingID, "Missing ankety ID")
+		return
+	}
+
+	var in Input
+	sent, err := request.Decode(w, r, &in)
+	if err != nil {
+		request.WriteError(w, err)
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	anketyList, err := LoadUser()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading ankety")
+		return
+	}
+
+	var updated Ankety
+	found := false
+	for i := range anketyList {
+		if anketyList[i].Id != anketyID {
+			continue
+		}
+		if anketyList[i].UserId != userID {
+			problem.Write(w, http.StatusForbidden, problem.CodeForbidden, "Cannot edit other user's ankety")
+			return
+		}
+		if err := baseline(anketyList[i]); err != nil {
+			problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving revision")
+			return
+		}
+
+		a := anketyList[i]
+		replace := r.Method == http.MethodPut
+		fields := map[string]struct{ dst, value *string }{
+			"name":       {&a.Name, &in.Name},
+			"gender":     {&a.Gender, &in.Gender},
+			"birth_date": {&a.BirthDate, &in.BirthDate},
+			"job":        {&a.Job, &in.Job},
+			"school":     {&a.School, &in.School},
+		}
+		for key, field := range fields {
+			if replace || sent.Has(key) {
+				*field.dst = *field.value
+			}
+		}
+		if sent.Has("birth_date") {
+			a.BirthDateApprox = false
+		}
+		parseProfile(in, sent, &a, replace)
+		parsePrivacy(in, sent, &a, replace)
+		if err := validateAnkety(&a); err != nil {
+			request.WriteError(w, err)
+			return
+		}
+		if sent.Has("school") || replace {
+			if err := resolveSchool(in.SchoolID, &a); err != nil {
+				request.WriteError(w, err)
+				return
+			}
+		}
+		hidden := a.Moderation.Hidden
+		a.Moderation, err = moderation.Submit(moderationContent(a))
+		if err != nil {
+			problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Moderation error")
+			return
+		}
+		a.Moderation.Hidden = hidden
+
+		anketyList[i] = a
+		updated = a
+		found = true
+		break
+	}
+
+	if !found {
+		problem.Write(w, http.StatusNotFound, problem.CodeAnketyNotFound, "Ankety not found")
+		return
+	}
+
+	if err := SaveAnkety(anketyList); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
+		return
+	}
+	recordRevision(updated, 0)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// DeleteHandler удаляет анкету в корзину, если она принадлежит текущему пользователю
+func DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	anketyID := r.PathValue("id")
+	if anketyID == "" {
+		problem.Write(w, http.StatusBadRequest, problem.CodeMissingID, "Missing ankety ID")
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	anketyList, err := LoadUser()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading ankety")
+		return
+	}
+
+	remaining := make([]Ankety, 0, len(anketyList))
+	var deleted *Ankety
+	for _, a := range anketyList {
+		if a.Id == anketyID {
+			if a.UserId != userID {
+				problem.Write(w, http.StatusForbidden, problem.CodeForbidden, "You can only delete your own ankety")
+				return
+			}
+			deleted = &a
+			continue
+		}
+		remaining = append(remaining, a)
+	}
+
+	if deleted == nil {
+		problem.Write(w, http.StatusNotFound, problem.CodeAnketyNotFound, "Ankety not found")
+		return
+	}
+
+	// Анкета переносится в корзину; история правок удаляется вместе с ней
+	// при окончательном удалении, см. trash.OnPurge в main
+	now := time.Now()
+	deleted.DeletedAt = &now
+	err = trash.Put(trash.Item{
+		Kind: trash.KindAnkety, ObjectID: deleted.Id, OwnerID: deleted.UserId,
+		Title: deleted.Name, DeletedBy: userID, DeletedAt: now,
+	}, deleted)
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving trash")
+		return
+	}
+	if err := SaveAnkety(remaining); err != nil {
+		if err := trash.Remove(trash.KindAnkety, deleted.Id); err != nil {
+			fmt.Printf("Ошибка отмены удаления в корзину: %v\n", err)
+		}
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,169 @@
+package ankety
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"talant/auth"
+	"talant/problem"
+	"talant/storage"
+	"talant/trash"
+)
+
+// UploadResumeHandler загружает PDF-резюме к анкете (/ankety/{id}/attachments/resume)
+func UploadResumeHandler(w http.ResponseWriter, r *http.Request) {
+	upload(w, r, "file", storage.ResumeLimits, func(a *Ankety, f storage.File) *storage.File {
+		old := a.Resume
+		a.Resume = &f
+		return old
+	})
+}
+
+// UploadPhotoHandler загружает фото кандидата (/ankety/{id}/attachments/photo)
+func UploadPhotoHandler(w http.ResponseWriter, r *http.Request) {
+	upload(w, r, "file", storage.ImageLimits, func(a *Ankety, f storage.File) *storage.File {
+		old := a.Photo
+		a.Photo = &f
+		return old
+	})
+}
+
+// upload принимает файл и сохраняет его метаданные в анкете владельца. attach
+// возвращает замененный файл: он удаляется, если на него больше никто не ссылается.
+func upload(w http.ResponseWriter, r *http.Request, field string, lim storage.Limits, attach func(*Ankety, storage.File) *storage.File) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+	anketyID := r.PathValue("id")
+
+	// Проверяем права до приема файла, чтобы не сохранять чужие загрузки
+	anketyList, err := LoadUser()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading ankety")
+		return
+	}
+	if status, code, msg := checkOwner(anketyList, anketyID, userID); status != http.StatusOK {
+		problem.Write(w, status, code, msg)
+		return
+	}
+
+	file, err := storage.Receive(w, r, field, lim)
+	if err != nil {
+		storage.UploadError(w, err)
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	anketyList, err = LoadUser()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading ankety")
+		return
+	}
+	if status, code, msg := checkOwner(anketyList, anketyID, userID); status != http.StatusOK {
+		problem.Write(w, status, code, msg)
+		return
+	}
+	var replaced *storage.File
+	for i := range anketyList {
+		if anketyList[i].Id == anketyID {
+			replaced = attach(&anketyList[i], file)
+			break
+		}
+	}
+	if err := SaveAnkety(anketyList); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
+		return
+	}
+	if err := storage.Release(replaced.Keys()...); err != nil {
+		fmt.Printf("Ошибка удаления замененного файла: %v\n", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(file)
+}
+
+// checkOwner возвращает http.StatusOK, если анкета существует и принадлежит userID
+func checkOwner(anketyList []Ankety, anketyID, userID string) (int, string, string) {
+	for _, a := range anketyList {
+		if a.Id == anketyID {
+			if a.UserId != userID {
+				return http.StatusForbidden, problem.CodeForbidden, "Cannot edit other user's ankety"
+			}
+			return http.StatusOK, "", ""
+		}
+	}
+	return http.StatusNotFound, problem.CodeAnketyNotFound, "Ankety not found"
+}
+
+// AttachmentHandler перенаправляет пользователя, которому видна анкета, на временную ссылку
+// (/ankety/{id}/attachments/{kind}, kind: resume, photo, photo-thumb)
+func AttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	v := viewerFrom(r)
+	if v.ID == "" {
+		problem.Write(w, http.StatusUnauthorized, problem.CodeUnauthorized, "Missing token")
+		return
+	}
+
+	anketyList, err := LoadUser()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading ankety")
+		return
+	}
+
+	anketyID := r.PathValue("id")
+	for _, a := range anketyList {
+		if a.Id != anketyID || !canView(a, v) {
+			continue
+		}
+		switch kind := r.PathValue("kind"); {
+		case kind == "resume" && a.Resume != nil:
+			storage.RedirectToFile(w, r, a.Resume.Key, a.Resume.Name)
+		case kind == "photo" && a.Photo != nil:
+			storage.RedirectToFile(w, r, a.Photo.Key, a.Photo.Name)
+		case kind == "photo-thumb" && a.Photo != nil:
+			storage.RedirectToFile(w, r, a.Photo.ThumbKey, "thumb.jpg")
+		default:
+			problem.Write(w, http.StatusNotFound, problem.CodeFileNotFound, "File not found")
+		}
+		return
+	}
+	problem.Write(w, http.StatusNotFound, problem.CodeAnketyNotFound, "Ankety not found")
+}
+
+// FileKeys возвращает ключи резюме и фото всех анкет, см. storage.OnReferences
+func FileKeys() ([]string, error) {
+	anketyList, err := LoadUser()
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, a := range anketyList {
+		keys = append(keys, a.Resume.Keys()...)
+		keys = append(keys, a.Photo.Keys()...)
+	}
+	return keys, nil
+}
+
+// ReleaseFiles удаляет резюме и фото окончательно удаленной анкеты, если на те же
+// файлы больше никто не ссылается (trash.OnPurge)
+func ReleaseFiles(it trash.Item) error {
+	var a Ankety
+	if err := json.Unmarshal(it.Content, &a); err != nil {
+		return err
+	}
+	return storage.Release(append(a.Resume.Keys(), a.Photo.Keys()...)...)
+}
@@ -0,0 +1,90 @@
+package ankety
+
+import (
+	"strings"
+	"talant/moderation"
+)
+
+// moderationContent - проверяемый текст анкеты. Ссылки и контакты из отдельных
+// полей не проверяются: для них эти поля и предназначены.
+func moderationContent(a Ankety) moderation.Content {
+	var programs, experience, skills []string
+	for _, e := range a.Education {
+		programs = append(programs, e.School, e.Program)
+	}
+	for _, e := range a.Experience {
+		experience = append(experience, e.Company, e.Position, e.Description)
+	}
+	for _, s := range a.Skills {
+		skills = append(skills, s.Name)
+	}
+	return moderation.Content{
+		Kind:     moderation.KindAnkety,
+		ID:       a.Id,
+		AuthorID: a.UserId,
+		Title:    a.Name,
+		Fields: map[string]string{
+			"name":       a.Name,
+			"job":        a.Job,
+			"school":     a.School,
+			"education":  strings.Join(programs, "\n"),
+			"experience": strings.Join(experience, "\n"),
+			"skills":     strings.Join(skills, "\n"),
+		},
+	}
+}
+
+// ApplyModeration сохраняет решение модератора в анкете
+func ApplyModeration(anketyID string, s moderation.Status) error {
+	mu.Lock()
+	defer mu.Unlock()
+	anketyList, err := LoadUser()
+	if err != nil {
+		return err
+	}
+	for i := range anketyList {
+		if anketyList[i].Id == anketyID {
+			s.Hidden = anketyList[i].Moderation.Hidden
+			anketyList[i].Moderation = s
+			return SaveAnkety(anketyList)
+		}
+	}
+	return nil
+}
+
+// moderateUnchecked отправляет на проверку анкету без состояния модерации,
+// как одноименная функция пакета job
+func moderateUnchecked(a *Ankety) (bool, error) {
+	if a.Moderation.State != "" {
+		return false, nil
+	}
+	status, err := moderation.Submit(moderationContent(*a))
+	if err != nil {
+		return false, err
+	}
+	status.Hidden = a.Moderation.Hidden
+	a.Moderation = status
+	return true, nil
+}
+
+// ModerateExisting проверяет сохраненные анкеты без состояния модерации
+func ModerateExisting() error {
+	mu.Lock()
+	defer mu.Unlock()
+	anketyList, err := LoadUser()
+	if err != nil {
+		return err
+	}
+	changed := false
+	for i := range anketyList {
+		ok, err := moderateUnchecked(&anketyList[i])
+		if err != nil {
+			return err
+		}
+		changed = changed || ok
+	}
+	if !changed {
+		return nil
+	}
+	return SaveAnkety(anketyList)
+}
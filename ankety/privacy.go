@@ -0,0 +1,245 @@
+package ankety
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"talant/application"
+	"talant/auth"
+	"talant/problem"
+	"talant/request"
+)
+
+// Видимость анкеты
+const (
+	VisibilityPublic    = "public"    // всем, в том числе без авторизации
+	VisibilityEmployers = "employers" // только работодателям
+	VisibilityApplied   = "applied"   // только работодателям, на чьи вакансии кандидат откликнулся
+	VisibilityHidden    = "hidden"    // только самому кандидату
+)
+
+const defaultVisibility = VisibilityEmployers
+
+// Contacts - контакты кандидата. Другим пользователям они показываются
+// замаскированными, пока кандидат не разрешит их показ конкретному работодателю.
+type Contacts struct {
+	Email    string `json:"email,omitempty" validate:"trim,email,max=254"`
+	Phone    string `json:"phone,omitempty" validate:"trim,max=32"`
+	Telegram string `json:"telegram,omitempty" validate:"trim,max=64"`
+}
+
+// viewer - тот, кто смотрит анкету. Пустой ID - неавторизованный посетитель.
+type viewer struct {
+	ID       string
+	Role     string
+	Verified bool
+}
+
+func viewerFrom(r *http.Request) viewer {
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		return viewer{}
+	}
+	return viewerOf(userID)
+}
+
+func viewerOf(userID string) viewer {
+	return viewer{ID: userID, Role: auth.RoleOf(userID), Verified: auth.IsVerified(userID)}
+}
+
+// canView проверяет настройки видимости анкеты для пользователя.
+// Анкеты несовершеннолетних, кроме владельца, видят только проверенные работодатели.
+func canView(a Ankety, v viewer) bool {
+	if v.ID != "" && v.ID == a.UserId {
+		return true
+	}
+	// Непроверенную анкету модератор видит в очереди, остальным она не показывается
+	if !a.Moderation.Visible() {
+		return false
+	}
+	if a.Minor && (v.Role != auth.RoleEmployer || !v.Verified) {
+		return false
+	}
+	switch a.Visibility {
+	case VisibilityPublic:
+		return true
+	case VisibilityEmployers:
+		return v.Role == auth.RoleEmployer
+	case VisibilityApplied:
+		return v.Role == auth.RoleEmployer && application.HasApplied(a.UserId, v.ID)
+	}
+	return false
+}
+
+// present возвращает копию анкеты в том виде, в котором ее можно показать пользователю
+func present(a Ankety, v viewer) Ankety {
+	if v.ID != "" && v.ID == a.UserId {
+		return a
+	}
+	if v.ID == "" || !slices.Contains(a.ContactsSharedWith, v.ID) {
+		a.Contacts = Contacts{
+			Email:    maskEmail(a.Contacts.Email),
+			Phone:    maskTail(a.Contacts.Phone, 2),
+			Telegram: maskTail(a.Contacts.Telegram, 0),
+		}
+	}
+	a.ContactsSharedWith = nil
+	// Точная дата рождения видна только владельцу, остальным - возраст и признак Minor
+	a.BirthDate, a.BirthDateApprox = "", false
+	if a.Guardian != nil {
+		// Работодателю достаточно знать, что согласие получено
+		a.Guardian = &GuardianConsent{GivenAt: a.Guardian.GivenAt}
+	}
+	return a
+}
+
+func maskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return maskTail(email, 0)
+	}
+	if local == "" {
+		return "***@" + domain
+	}
+	return string([]rune(local)[:1]) + "***@" + domain
+}
+
+// maskTail заменяет значение звездочками, оставляя последние keep символов
+func maskTail(s string, keep int) string {
+	if s == "" {
+		return ""
+	}
+	r := []rune(s)
+	if keep >= len(r) {
+		keep = 0
+	}
+	return "***" + string(r[len(r)-keep:])
+}
+
+// parsePrivacy читает из запроса видимость и контакты, правила те же, что у parseProfile
+func parsePrivacy(in Input, sent request.Fields, a *Ankety, replace bool) {
+	if sent.Has("visibility") || replace {
+		a.Visibility = in.Visibility
+		if a.Visibility == "" {
+			a.Visibility = defaultVisibility
+		}
+	}
+
+	contacts := map[string]struct{ dst, value *string }{
+		"email":    {&a.Contacts.Email, &in.Email},
+		"phone":    {&a.Contacts.Phone, &in.Phone},
+		"telegram": {&a.Contacts.Telegram, &in.Telegram},
+	}
+	for key, field := range contacts {
+		if sent.Has(key) || replace {
+			*field.dst = *field.value
+		}
+	}
+}
+
+// VisibleTo возвращает анкеты, которые может видеть автор запроса, в показываемом ему виде
+func VisibleTo(r *http.Request) ([]Ankety, error) {
+	anketyList, err := LoadUser()
+	if err != nil {
+		return nil, err
+	}
+	v := viewerFrom(r)
+	visible := []Ankety{}
+	for _, a := range anketyList {
+		if canView(a, v) {
+			visible = append(visible, present(a, v))
+		}
+	}
+	return visible, nil
+}
+
+// FindByUser возвращает анкету пользователя или nil, если ее нет.
+// Если анкет несколько - ErrDuplicateAnkety.
+func FindByUser(userID string) (*Ankety, error) {
+	anketyList, err := LoadUser()
+	if err != nil {
+		return nil, err
+	}
+	i, err := ofUser(anketyList, userID)
+	if err != nil || i < 0 {
+		return nil, err
+	}
+	return &anketyList[i], nil
+}
+
+// ConsentInput - тело запроса разрешения на показ контактов
+type ConsentInput struct {
+	EmployerID string `json:"employer_id"`
+}
+
+// ConsentHandler управляет согласием на показ контактов работодателю
+// (POST /ankety/{id}/consent с employer_id, DELETE /ankety/{id}/consent/{employer_id})
+func ConsentHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	employerID := r.PathValue("employer_id")
+	if r.Method == http.MethodPost {
+		var in ConsentInput
+		if _, err := request.Decode(w, r, &in); err != nil {
+			request.WriteError(w, err)
+			return
+		}
+		employerID = in.EmployerID
+	}
+	if err := request.Require("employer_id", employerID); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	anketyList, err := LoadUser()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading ankety")
+		return
+	}
+	anketyID := r.PathValue("id")
+	if status, code, msg := checkOwner(anketyList, anketyID, userID); status != http.StatusOK {
+		problem.Write(w, status, code, msg)
+		return
+	}
+
+	var updated Ankety
+	for i := range anketyList {
+		if anketyList[i].Id != anketyID {
+			continue
+		}
+		shared := slices.DeleteFunc(anketyList[i].ContactsSharedWith, func(id string) bool {
+			return id == employerID
+		})
+		if r.Method == http.MethodPost {
+			shared = append(shared, employerID)
+		}
+		anketyList[i].ContactsSharedWith = shared
+		updated = anketyList[i]
+		break
+	}
+
+	if err := SaveAnkety(anketyList); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
+		return
+	}
+
+	// Пустой список - [], а не null: ответ описан как массив
+	shared := updated.ContactsSharedWith
+	if shared == nil {
+		shared = []string{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shared)
+}
@@ -0,0 +1,167 @@
+package ankety
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"talant/request"
+	"time"
+)
+
+// Education - запись об учебе: учебное заведение, программа и годы обучения
+type Education struct {
+	School    string `json:"school" validate:"trim,required,max=200"`
+	Program   string `json:"program" validate:"trim,max=200"`
+	StartYear int    `json:"start_year,omitempty"`
+	EndYear   int    `json:"end_year,omitempty"`
+}
+
+// Experience - опыт работы или стажировки
+type Experience struct {
+	Kind        string `json:"kind" validate:"required,oneof=work internship"`
+	Company     string `json:"company" validate:"trim,required,max=200"`
+	Position    string `json:"position" validate:"trim,required,max=200"`
+	StartYear   int    `json:"start_year,omitempty"`
+	EndYear     int    `json:"end_year,omitempty"`
+	Description string `json:"description,omitempty" validate:"trim,max=2000"`
+}
+
+// Skill - навык с уровнем владения
+type Skill struct {
+	Name  string `json:"name" validate:"trim,required,max=50"`
+	Level string `json:"level" validate:"required,oneof=beginner intermediate advanced expert"`
+}
+
+// Language - язык с уровнем по шкале CEFR или "native"
+type Language struct {
+	Name  string `json:"name" validate:"trim,required,max=50"`
+	Level string `json:"level" validate:"required,oneof=A1 A2 B1 B2 C1 C2 native"`
+}
+
+// Links - ссылки на портфолио и GitHub
+type Links struct {
+	Portfolio string `json:"portfolio,omitempty" validate:"trim,url"`
+	GitHub    string `json:"github,omitempty" validate:"trim,url"`
+}
+
+// currentSchema - версия формата анкеты. Записи со старой версией
+// приводятся к новому виду в migrate при загрузке.
+const currentSchema = 4
+
+// migrate переводит анкету старого формата в текущий: v2 добавила структурированный
+// профиль вместо одной строки school, v3 - настройки видимости, v4 - дату рождения вместо возраста
+func migrate(a *Ankety) {
+	if a.Schema >= currentSchema {
+		return
+	}
+	if len(a.Education) == 0 && a.School != "" {
+		a.Education = []Education{{School: a.School}}
+	}
+	if a.Experience == nil {
+		a.Experience = []Experience{}
+	}
+	if a.Skills == nil {
+		a.Skills = []Skill{}
+	}
+	if a.Languages == nil {
+		a.Languages = []Language{}
+	}
+	if a.Visibility == "" {
+		a.Visibility = defaultVisibility
+	}
+	migrateBirthDate(a)
+	a.Schema = currentSchema
+}
+
+// parseProfile заполняет структурированные поля анкеты из запроса.
+// В формах списки education, experience, skills и languages передаются JSON-массивами.
+// При replace=true (PUT, создание) отсутствующие поля очищаются,
+// иначе (PATCH) меняются только переданные. Проверка - в validateAnkety.
+func parseProfile(in Input, sent request.Fields, a *Ankety, replace bool) {
+	if sent.Has("education") || replace {
+		a.Education = orEmpty(in.Education)
+	}
+	if sent.Has("experience") || replace {
+		a.Experience = orEmpty(in.Experience)
+	}
+	if sent.Has("skills") || replace {
+		a.Skills = orEmpty(in.Skills)
+	}
+	if sent.Has("languages") || replace {
+		a.Languages = orEmpty(in.Languages)
+	}
+	if sent.Has("salary_expectation") || replace {
+		a.SalaryExpectation = in.SalaryExpectation
+	}
+	if sent.Has("portfolio") || replace {
+		a.Links.Portfolio = in.Portfolio
+	}
+	if sent.Has("github") || replace {
+		a.Links.GitHub = in.GitHub
+	}
+}
+
+// orEmpty заменяет непереданный список пустым, чтобы в JSON он был [], а не null
+func orEmpty[T any](list []T) []T {
+	if list == nil {
+		return []T{}
+	}
+	return list
+}
+
+// validateProfile проверяет то, что не выражается правилами в тегах: годы,
+// повторы навыков и домен ссылки на GitHub. Возвращает все нарушения сразу.
+func validateProfile(a *Ankety) error {
+	var errs []error
+	for i, e := range a.Education {
+		if err := validateYears(e.StartYear, e.EndYear); err != nil {
+			errs = append(errs, request.Invalid(fmt.Sprintf("education[%d]", i), err.Error()))
+		}
+	}
+	for i, e := range a.Experience {
+		if err := validateYears(e.StartYear, e.EndYear); err != nil {
+			errs = append(errs, request.Invalid(fmt.Sprintf("experience[%d]", i), err.Error()))
+		}
+	}
+
+	seen := map[string]bool{}
+	for i, s := range a.Skills {
+		name := strings.ToLower(strings.TrimSpace(s.Name))
+		if name != "" && seen[name] {
+			errs = append(errs, request.Invalid(fmt.Sprintf("skills[%d].name", i), fmt.Sprintf("duplicate skill %q", s.Name)))
+		}
+		seen[name] = true
+	}
+
+	if err := validateHost(a.Links.GitHub, "github.com"); err != nil {
+		errs = append(errs, request.Invalid("github", err.Error()))
+	}
+	return request.Join(errs...)
+}
+
+func validateYears(start, end int) error {
+	maxYear := time.Now().Year() + 10
+	if start != 0 && (start < 1950 || start > maxYear) {
+		return fmt.Errorf("start_year out of range")
+	}
+	if end != 0 && (end < 1950 || end > maxYear) {
+		return fmt.Errorf("end_year out of range")
+	}
+	if start != 0 && end != 0 && end < start {
+		return fmt.Errorf("end_year is before start_year")
+	}
+	return nil
+}
+
+// validateHost проверяет, что ссылка ведет на домен host. Формат ссылки
+// проверяется правилом url в тегах Links.
+func validateHost(raw, host string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil
+	}
+	if strings.TrimPrefix(strings.ToLower(u.Host), "www.") != host {
+		return fmt.Errorf("must point to %s", host)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package ankety
+
+// ReportTarget возвращает владельца анкеты для жалобы или "", если анкеты нет
+// или жалующийся не может ее видеть
+func ReportTarget(anketyID, reporterID string) (string, error) {
+	anketyList, err := LoadUser()
+	if err != nil {
+		return "", err
+	}
+	for _, a := range anketyList {
+		if a.Id == anketyID && canView(a, viewerOf(reporterID)) {
+			return a.UserId, nil
+		}
+	}
+	return "", nil
+}
+
+// SetHidden скрывает анкету по жалобам или возвращает ее в показ
+func SetHidden(anketyID string, hidden bool) error {
+	mu.Lock()
+	defer mu.Unlock()
+	anketyList, err := LoadUser()
+	if err != nil {
+		return err
+	}
+	for i := range anketyList {
+		if anketyList[i].Id == anketyID {
+			anketyList[i].Moderation.Hidden = hidden
+			return SaveAnkety(anketyList)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+package ankety
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"talant/problem"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Шрифты DejaVu нужны PDF-резюме для кириллицы; они встроены в бинарник,
+// чтобы не зависеть от шрифтов системы. Лицензия - fonts/LICENSE.
+var (
+	//go:embed fonts/DejaVuSans.ttf
+	fontRegular []byte
+	//go:embed fonts/DejaVuSans-Bold.ttf
+	fontBold []byte
+)
+
+// resumeTemplate - оформление резюме. HTML и PDF строятся по одним данным,
+// но каждый шаблон задает свою разметку и акцентный цвет.
+type resumeTemplate struct {
+	html   *template.Template
+	accent [3]int
+}
+
+var resumeTemplates = map[string]resumeTemplate{
+	"classic": {html: template.Must(template.New("classic").Parse(classicHTML)), accent: [3]int{40, 40, 40}},
+	"modern":  {html: template.Must(template.New("modern").Parse(modernHTML)), accent: [3]int{33, 102, 172}},
+}
+
+const defaultResumeTemplate = "classic"
+
+var skillLevelNames = map[string]string{
+	"beginner":     "начальный",
+	"intermediate": "средний",
+	"advanced":     "продвинутый",
+	"expert":       "эксперт",
+}
+
+var genderNames = map[string]string{"male": "мужской", "female": "женский"}
+
+// resumeSection - раздел резюме: заголовок и строки
+type resumeSection struct {
+	Title string
+	Items []string
+}
+
+// resumeView - данные резюме, общие для HTML и PDF
+type resumeView struct {
+	Name     string
+	Subtitle string
+	Sections []resumeSection
+}
+
+func years(start, end int) string {
+	switch {
+	case start != 0 && end != 0:
+		return fmt.Sprintf("%d–%d", start, end)
+	case start != 0:
+		return fmt.Sprintf("с %d", start)
+	case end != 0:
+		return fmt.Sprintf("до %d", end)
+	}
+	return ""
+}
+
+// joinNonEmpty склеивает непустые части через sep
+func joinNonEmpty(sep string, parts ...string) string {
+	var out []string
+	for _, p := range parts {
+		if strings.TrimSpace(p) != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, sep)
+}
+
+func newResumeView(a Ankety) resumeView {
+	age := ""
+	if a.Age != "" {
+		age = a.Age + " лет"
+	}
+	v := resumeView{
+		Name:     a.Name,
+		Subtitle: joinNonEmpty(" · ", a.Job, genderNames[a.Gender], age),
+	}
+
+	var edu []string
+	for _, e := range a.Education {
+		edu = append(edu, joinNonEmpty(", ", e.School, e.Program, years(e.StartYear, e.EndYear)))
+	}
+	if len(edu) == 0 && a.School != "" {
+		edu = append(edu, a.School)
+	}
+
+	var exp []string
+	for _, e := range a.Experience {
+		kind := "Работа"
+		if e.Kind == "internship" {
+			kind = "Стажировка"
+		}
+		line := joinNonEmpty(", ", kind+": "+e.Position, e.Company, years(e.StartYear, e.EndYear))
+		exp = append(exp, joinNonEmpty(". ", line, e.Description))
+	}
+
+	var skills []string
+	for _, s := range a.Skills {
+		skills = append(skills, joinNonEmpty(" — ", s.Name, skillLevelNames[s.Level]))
+	}
+
+	var langs []string
+	for _, l := range a.Languages {
+		langs = append(langs, joinNonEmpty(" — ", l.Name, l.Level))
+	}
+
+	var contacts []string
+	for _, c := range [][2]string{
+		{"Email", a.Contacts.Email},
+		{"Телефон", a.Contacts.Phone},
+		{"Telegram", a.Contacts.Telegram},
+	} {
+		if c[1] != "" {
+			contacts = append(contacts, c[0]+": "+c[1])
+		}
+	}
+
+	var links []string
+	if a.Links.Portfolio != "" {
+		links = append(links, "Портфолио: "+a.Links.Portfolio)
+	}
+	if a.Links.GitHub != "" {
+		links = append(links, "GitHub: "+a.Links.GitHub)
+	}
+
+	for _, s := range []resumeSection{
+		{"Образование", edu},
+		{"Опыт", exp},
+		{"Навыки", skills},
+		{"Языки", langs},
+		{"Ссылки", links},
+		{"Контакты", contacts},
+	} {
+		if len(s.Items) > 0 {
+			v.Sections = append(v.Sections, s)
+		}
+	}
+	return v
+}
+
+// ResumeHandler отдает резюме, собранное из анкеты (/ankety/{id}/resume).
+// Параметры: format=html|pdf (по умолчанию html), template=classic|modern.
+func ResumeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	v := viewerFrom(r)
+	if v.ID == "" {
+		problem.Write(w, http.StatusUnauthorized, problem.CodeUnauthorized, "Missing token")
+		return
+	}
+
+	name := r.URL.Query().Get("template")
+	if name == "" {
+		name = defaultResumeTemplate
+	}
+	tmpl, ok := resumeTemplates[name]
+	if !ok {
+		problem.Write(w, http.StatusBadRequest, problem.CodeInvalidParameter, "Unknown template")
+		return
+	}
+
+	anketyList, err := LoadUser()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading ankety")
+		return
+	}
+	var found *Ankety
+	for i := range anketyList {
+		if anketyList[i].Id == r.PathValue("id") && canView(anketyList[i], v) {
+			shown := present(anketyList[i], v)
+			found = &shown
+			break
+		}
+	}
+	if found == nil {
+		problem.Write(w, http.StatusNotFound, problem.CodeAnketyNotFound, "Ankety not found")
+		return
+	}
+	view := newResumeView(*found)
+
+	var buf bytes.Buffer
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "html":
+		if err := tmpl.html.Execute(&buf, view); err != nil {
+			problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error rendering resume")
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	case "pdf":
+		if err := renderResumePDF(&buf, view, tmpl); err != nil {
+			fmt.Printf("Ошибка генерации PDF: %v\n", err)
+			problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error rendering resume")
+			return
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", `inline; filename="resume.pdf"`)
+	default:
+		problem.Write(w, http.StatusBadRequest, problem.CodeInvalidParameter, "Unknown format")
+		return
+	}
+	w.Write(buf.Bytes())
+}
+
+func renderResumePDF(buf *bytes.Buffer, v resumeView, t resumeTemplate) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes("DejaVu", "", fontRegular)
+	pdf.AddUTF8FontFromBytes("DejaVu", "B", fontBold)
+	pdf.SetMargins(20, 20, 20)
+	pdf.AddPage()
+
+	r, g, b := t.accent[0], t.accent[1], t.accent[2]
+	pageW, _ := pdf.GetPageSize()
+	width := pageW - 40
+
+	pdf.SetTextColor(r, g, b)
+	pdf.SetFont("DejaVu", "B", 22)
+	pdf.MultiCell(width, 10, v.Name, "", "L", false)
+	pdf.SetTextColor(90, 90, 90)
+	pdf.SetFont("DejaVu", "", 11)
+	pdf.MultiCell(width, 6, v.Subtitle, "", "L", false)
+	pdf.SetDrawColor(r, g, b)
+	pdf.Line(20, pdf.GetY()+2, pageW-20, pdf.GetY()+2)
+	pdf.Ln(6)
+
+	for _, s := range v.Sections {
+		pdf.SetTextColor(r, g, b)
+		pdf.SetFont("DejaVu", "B", 13)
+		pdf.CellFormat(width, 8, s.Title, "", 1, "L", false, 0, "")
+		pdf.SetTextColor(20, 20, 20)
+		pdf.SetFont("DejaVu", "", 11)
+		for _, item := range s.Items {
+			pdf.MultiCell(width, 6, "• "+item, "", "L", false)
+		}
+		pdf.Ln(3)
+	}
+
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(buf)
+}
+
+const classicHTML = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>{{.Name}} — резюме</title>
+<style>
+body { font-family: Georgia, "Times New Roman", serif; max-width: 720px; margin: 40px auto; color: #222; }
+h1 { margin-bottom: 4px; }
+.subtitle { color: #666; border-bottom: 1px solid #222; padding-bottom: 12px; }
+h2 { font-size: 1.1em; text-transform: uppercase; letter-spacing: 1px; margin-top: 24px; }
+@media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+<div class="subtitle">{{.Subtitle}}</div>
+{{range .Sections}}<h2>{{.Title}}</h2>
+<ul>{{range .Items}}<li>{{.}}</li>{{end}}</ul>
+{{end}}</body>
+</html>
+`
+
+const modernHTML = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>{{.Name}} — резюме</title>
+<style>
+body { font-family: "Segoe UI", Arial, sans-serif; max-width: 760px; margin: 40px auto; color: #1d1d1f; }
+header { background: #2166ac; color: #fff; padding: 24px 28px; border-radius: 8px; }
+header h1 { margin: 0 0 6px; }
+section { padding: 0 28px; }
+h2 { color: #2166ac; font-size: 1.05em; margin-top: 24px; }
+ul { padding-left: 18px; }
+@media print { body { margin: 0; } header { border-radius: 0; } }
+</style>
+</head>
+<body>
+<header><h1>{{.Name}}</h1><div>{{.Subtitle}}</div></header>
+{{range .Sections}}<section><h2>{{.Title}}</h2>
+<ul>{{range .Items}}<li>{{.}}</li>{{end}}</ul></section>
+{{end}}</body>
+</html>
+`
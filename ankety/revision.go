@@ -0,0 +1,192 @@
+package ankety
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"talant/auth"
+	"talant/moderation"
+	"talant/problem"
+	"talant/revision"
+	"talant/school"
+	"time"
+)
+
+// revisionOmit - поля анкеты, которые не входят в ревизии: служебные, вычисляемые,
+// вложения и согласия. Видимость тоже не восстанавливается, чтобы откат текста
+// не открыл анкету шире, чем сейчас выбрал кандидат.
+var revisionOmit = []string{"id", "user_id", "age", "minor", "birth_date_approx", "resume", "photo",
+	"visibility", "contacts_shared_with", "guardian_consent", "moderation", "schema_version"}
+
+func snapshot(a Ankety, authorID string) (revision.Revision, error) {
+	content, err := revision.Snapshot(a, revisionOmit...)
+	if err != nil {
+		return revision.Revision{}, err
+	}
+	return revision.Revision{Kind: revision.KindAnkety, ObjectID: a.Id, AuthorID: authorID, CreatedAt: time.Now().UTC(), Content: content}, nil
+}
+
+// baseline сохраняет текущее состояние анкеты, созданной до ведения истории, ревизией 1.
+// Вызывается до изменения анкеты: списки в копиях структуры разделяют память.
+func baseline(a Ankety) error {
+	base, err := snapshot(a, a.UserId)
+	if err != nil {
+		return err
+	}
+	return revision.Baseline(base)
+}
+
+// recordRevision сохраняет правку анкеты; restoredFrom - номер восстановленной ревизии или 0.
+// Ошибка истории не отменяет уже сохраненную правку, поэтому только пишется в лог.
+func recordRevision(a Ankety, restoredFrom int) {
+	rev, err := snapshot(a, a.UserId)
+	if err == nil {
+		rev.RestoredFrom = restoredFrom
+		_, err = revision.Record(rev)
+	}
+	if err != nil {
+		fmt.Printf("Ошибка сохранения ревизии: %v\n", err)
+	}
+}
+
+// ownAnkety находит анкету текущего пользователя по ID из пути
+func ownAnkety(w http.ResponseWriter, r *http.Request) (*Ankety, bool) {
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return nil, false
+	}
+	anketyList, err := LoadUser()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading ankety")
+		return nil, false
+	}
+	if status, code, msg := checkOwner(anketyList, r.PathValue("id"), userID); status != http.StatusOK {
+		problem.Write(w, status, code, msg)
+		return nil, false
+	}
+	return &anketyList[indexOf(anketyList, r.PathValue("id"))], true
+}
+
+// indexOf возвращает индекс анкеты в списке или -1
+func indexOf(anketyList []Ankety, anketyID string) int {
+	for i := range anketyList {
+		if anketyList[i].Id == anketyID {
+			return i
+		}
+	}
+	return -1
+}
+
+// RevisionsHandler возвращает владельцу историю правок анкеты (GET /ankety/{id}/revisions)
+func RevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	a, ok := ownAnkety(w, r)
+	if !ok {
+		return
+	}
+	if err := baseline(*a); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading revisions")
+		return
+	}
+	revision.WriteList(w, revision.KindAnkety, a.Id)
+}
+
+// DiffHandler возвращает изменения полей между ревизиями анкеты (GET /ankety/{id}/diff?from=&to=)
+func DiffHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	a, ok := ownAnkety(w, r)
+	if !ok {
+		return
+	}
+	revision.WriteDiff(w, r, revision.KindAnkety, a.Id)
+}
+
+// RestoreHandler восстанавливает содержимое анкеты из ревизии
+// (POST /ankety/{id}/revisions/{number}/restore) и сохраняет его новой ревизией
+func RestoreHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+	number, err := strconv.Atoi(r.PathValue("number"))
+	if err != nil || number <= 0 {
+		problem.Write(w, http.StatusNotFound, problem.CodeRevisionNotFound, "Revision not found")
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	anketyList, err := LoadUser()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading ankety")
+		return
+	}
+	if status, code, msg := checkOwner(anketyList, r.PathValue("id"), userID); status != http.StatusOK {
+		problem.Write(w, status, code, msg)
+		return
+	}
+	idx := indexOf(anketyList, r.PathValue("id"))
+	rev, err := revision.Get(revision.KindAnkety, anketyList[idx].Id, number)
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading revisions")
+		return
+	}
+	if rev == nil {
+		problem.Write(w, http.StatusNotFound, problem.CodeRevisionNotFound, "Revision not found")
+		return
+	}
+
+	before, a := anketyList[idx], anketyList[idx]
+	a.Education, a.Experience, a.Skills, a.Languages = nil, nil, nil, nil
+	if err := json.Unmarshal(rev.Content, &a); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error reading revision")
+		return
+	}
+	if a.BirthDate != before.BirthDate {
+		a.BirthDateApprox = false
+	}
+	if err := validateAnkety(&a); err != nil {
+		problem.Write(w, http.StatusConflict, problem.CodeRestoreConflict, "Cannot restore revision: "+err.Error())
+		return
+	}
+	a.SchoolID, a.School, err = school.Resolve(a.SchoolID, a.School)
+	if errors.Is(err, school.ErrUnknownSchool) {
+		problem.Write(w, http.StatusConflict, problem.CodeRestoreConflict, "Cannot restore revision: "+err.Error())
+		return
+	}
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading schools")
+		return
+	}
+	a.Moderation, err = moderation.Submit(moderationContent(a))
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Moderation error")
+		return
+	}
+	a.Moderation.Hidden = before.Moderation.Hidden
+
+	anketyList[idx] = a
+	if err := SaveAnkety(anketyList); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
+		return
+	}
+	recordRevision(a, number)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a)
+}
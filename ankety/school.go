@@ -0,0 +1,62 @@
+package ankety
+
+import (
+	"encoding/json"
+	"net/http"
+	"talant/problem"
+	"talant/school"
+)
+
+// SchoolCandidatesHandler возвращает видимые автору запроса анкеты студентов
+// и выпускников заведения (GET /schools/{id}/candidates). Учитывается основное
+// заведение анкеты и места учебы из раздела "Образование".
+func SchoolCandidatesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	list, err := school.Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading schools")
+		return
+	}
+	var s *school.School
+	for i := range list {
+		if list[i].Id == r.PathValue("id") {
+			s = &list[i]
+			break
+		}
+	}
+	if s == nil {
+		problem.Write(w, http.StatusNotFound, problem.CodeSchoolNotFound, "School not found")
+		return
+	}
+
+	visible, err := VisibleTo(r)
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading ankety")
+		return
+	}
+	result := []Ankety{}
+	for _, a := range visible {
+		if studiedAt(list, *s, a) {
+			result = append(result, a)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func studiedAt(list []school.School, s school.School, a Ankety) bool {
+	if school.Belongs(list, s, a.SchoolID, a.School) {
+		return true
+	}
+	for _, e := range a.Education {
+		if school.Belongs(list, s, "", e.School) {
+			return true
+		}
+	}
+	return false
+}
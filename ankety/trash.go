@@ -0,0 +1,74 @@
+package ankety
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"talant/auth"
+	"talant/problem"
+	"talant/trash"
+	"time"
+)
+
+// UndeleteHandler восстанавливает анкету из корзины (POST /trash/ankety/{id}/restore).
+// Анкета возвращается с прежним ID, поэтому отклики с ней снова открываются работодателям.
+func UndeleteHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	item, err := trash.Get(trash.KindAnkety, r.PathValue("id"), time.Now())
+	if err != nil {
+		trash.Error(w, err)
+		return
+	}
+	if item.OwnerID != userID {
+		problem.Write(w, http.StatusForbidden, problem.CodeForbidden, "You can only restore your own ankety")
+		return
+	}
+	var a Ankety
+	if err := json.Unmarshal(item.Content, &a); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error reading trash")
+		return
+	}
+
+	anketyList, err := LoadUser()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading ankety")
+		return
+	}
+	for _, existing := range anketyList {
+		if existing.UserId == userID {
+			problem.Write(w, http.StatusConflict, problem.CodeAlreadyExists, "Ankety already exists for this user: delete it before restoring")
+			return
+		}
+	}
+
+	// Запись могла пролежать в корзине дольше смены формата и дня рождения
+	a.DeletedAt = nil
+	migrate(&a)
+	refreshAge(&a)
+	if _, err := moderateUnchecked(&a); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Moderation error")
+		return
+	}
+	if err := SaveAnkety(append(anketyList, a)); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
+		return
+	}
+	if err := trash.Remove(trash.KindAnkety, a.Id); err != nil {
+		fmt.Printf("Ошибка очистки корзины: %v\n", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a)
+}
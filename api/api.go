@@ -0,0 +1,104 @@
+// Package api - версионированный REST API /api/v1: пользователи, сессии, вакансии,
+// анкеты и отклики. Маршруты описаны таблицей routes; по ней же регистрируются
+// устаревшие адреса и строится документ OpenAPI, см. Spec.
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Prefix - общий префикс маршрутов первой версии API
+const Prefix = "/api/v1"
+
+// Route - маршрут API и его описание для OpenAPI
+type Route struct {
+	// Method и Path - метод и путь относительно Prefix, параметры пути - в {}
+	Method  string
+	Path    string
+	Handler http.HandlerFunc
+
+	// ID - operationId, Summary - краткое описание, Tag - раздел документа
+	ID      string
+	Summary string
+	Tag     string
+	// Auth - нужна кука auth_token, которую ставит POST /sessions
+	Auth bool
+	// Query - параметры строки запроса
+	Query []Param
+	// Body - значение типа тела запроса, nil - запрос без тела. MergePatch - тело
+	// в формате JSON Merge Patch (RFC 7396). Upload - поле файла multipart-формы.
+	Body       any
+	MergePatch bool
+	Upload     string
+	// Rules - значение типа, в который копируется тело; его правила validate
+	// описывают поля тела с теми же именами, см. request.Validate
+	Rules any
+
+	// Status - код успешного ответа, Response - значение типа его тела:
+	// nil - без тела, Text, Redirect и Binary - не JSON
+	Status   int
+	Response any
+
+	// Legacy - шаблон прежнего маршрута того же обработчика. Он продолжает работать,
+	// но отвечает с заголовками Deprecation и Link на новый адрес.
+	// Шаблон без метода, как "/singin", принимает любой метод.
+	Legacy string
+}
+
+// Param - параметр строки запроса
+type Param struct {
+	Name        string
+	Description string
+}
+
+// Text - ответ простым текстом
+type Text struct{}
+
+// Redirect - ответ 302 с временной ссылкой на файл в Location
+type Redirect struct{}
+
+// Binary - ответ файлом одного из типов Types, например HTML или PDF
+type Binary struct {
+	Types []string
+}
+
+// Register регистрирует маршруты API, их устаревшие адреса и документ OpenAPI
+func Register(mux *http.ServeMux) {
+	for _, rt := range routes {
+		mux.HandleFunc(rt.Method+" "+Prefix+rt.Path, rt.Handler)
+		if rt.Legacy != "" {
+			mux.HandleFunc(rt.Legacy, deprecated(rt))
+		}
+	}
+	mux.HandleFunc("GET "+Prefix+"/openapi.json", SpecHandler)
+}
+
+// deprecated оборачивает обработчик для прежнего адреса: ответ помечается
+// устаревшим (draft-ietf-httpapi-deprecation-header) и ссылается на адрес в /api/v1
+func deprecated(rt Route) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor(rt, r)))
+		rt.Handler(w, r)
+	}
+}
+
+var pathParam = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// successor подставляет в новый путь значения параметров из запроса к прежнему
+func successor(rt Route, r *http.Request) string {
+	return Prefix + pathParam.ReplaceAllStringFunc(rt.Path, func(m string) string {
+		return r.PathValue(strings.Trim(m, "{}"))
+	})
+}
+
+// legacyPattern разбирает Route.Legacy на метод и путь; без метода - метод нового маршрута
+func legacyPattern(rt Route) (method, path string) {
+	if method, path, ok := strings.Cut(rt.Legacy, " "); ok {
+		return method, path
+	}
+	return rt.Method, rt.Legacy
+}
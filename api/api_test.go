@@ -0,0 +1,564 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"maps"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"talant/auth"
+	"talant/storage"
+	"testing"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// typeErrors - сообщения request.FromJSON о значении не того типа
+var typeErrors = []string{"must be a string", "must be true or false", "must be an integer",
+	"must be a number", "must be an array", "must be an object"}
+
+// fixture - пользователи и объекты, на которых вызываются операции документа
+type fixture struct {
+	doc       Document
+	mux       *http.ServeMux
+	employer  *http.Cookie
+	candidate *http.Cookie
+	params    map[string]string
+	seq       int
+}
+
+// call - вызов операции: пользователь, параметры пути и поля тела поверх примера
+type call struct {
+	method, path string
+	cookie       *http.Cookie
+	params       map[string]string
+	fields       map[string]any
+}
+
+// setups готовят вызовы операций, которым нужен свой объект или пользователь:
+// каждая операция вызывается дважды, по основному и устаревшему адресу, а
+// удаление, вход и отклик нельзя повторить на тех же данных
+var setups = map[string]func(f *fixture, t *testing.T, c *call){
+	"logIn": func(f *fixture, t *testing.T, c *call) {
+		c.cookie = nil
+		c.fields = map[string]any{"username": "employer1", "password": "password1"}
+	},
+	"logOut": func(f *fixture, t *testing.T, c *call) {
+		c.cookie = f.newUser(t, "candidate")
+	},
+	"deleteJob": func(f *fixture, t *testing.T, c *call) {
+		c.cookie = f.newUser(t, "employer")
+		c.params = map[string]string{"job": f.newJob(t, c.cookie)}
+	},
+	"applyToJob": func(f *fixture, t *testing.T, c *call) {
+		c.cookie = f.newUser(t, "candidate")
+		f.newAnkety(t, c.cookie, adultBirthDate)
+	},
+	"createAnkety": func(f *fixture, t *testing.T, c *call) {
+		c.cookie = f.newUser(t, "candidate")
+	},
+	"deleteAnkety": func(f *fixture, t *testing.T, c *call) {
+		c.cookie = f.newUser(t, "candidate")
+		c.params = map[string]string{"ankety": f.newAnkety(t, c.cookie, adultBirthDate)}
+	},
+	"giveGuardianConsent": func(f *fixture, t *testing.T, c *call) {
+		c.cookie = f.newUser(t, "candidate")
+		minor := time.Now().AddDate(-15, 0, 0).Format(time.DateOnly)
+		c.params = map[string]string{"ankety": f.newAnkety(t, c.cookie, minor)}
+	},
+	"shareContacts": func(f *fixture, t *testing.T, c *call) {
+		c.fields = map[string]any{"employer_id": f.params["employer_id"]}
+	},
+}
+
+// adultBirthDate - дата рождения совершеннолетнего кандидата
+var adultBirthDate = time.Now().AddDate(-20, 0, 0).Format(time.DateOnly)
+
+// exampleValues - значения полей, которые пример по схеме не угадает: годы
+// и даты в допустимых пределах, ссылка на github.com. Поля со значением nil
+// в пример не попадают: company_id и school_id ссылаются на справочники.
+var exampleValues = map[string]any{
+	"birth_date": adultBirthDate,
+	"expires_at": time.Now().AddDate(0, 1, 0).Format(time.DateOnly),
+	"start_year": 2015,
+	"end_year":   2019,
+	"github":     "https://github.com/example",
+	"company_id": nil,
+	"school_id":  nil,
+}
+
+// TestHandlersMatchSpec вызывает каждую операцию документа OpenAPI, в том числе по
+// устаревшим адресам, с допустимым телом и проверяет, что обработчики с ним согласны:
+// операция выполняется успешно, а ответ имеет описанные код, тип и поля.
+func TestHandlersMatchSpec(t *testing.T) {
+	f := newFixture(t)
+
+	var calls []*call
+	for path, ops := range f.doc.Paths {
+		for method := range ops {
+			calls = append(calls, &call{method: method, path: path})
+		}
+	}
+	// Сначала создание и загрузка файлов, затем правки, чтение и восстановление
+	// версий, удаление - в конце
+	sort.Slice(calls, func(i, j int) bool {
+		pi, pj := f.phase(calls[i]), f.phase(calls[j])
+		if pi != pj {
+			return pi < pj
+		}
+		if calls[i].path != calls[j].path {
+			return calls[i].path < calls[j].path
+		}
+		return calls[i].method < calls[j].method
+	})
+
+	for _, c := range calls {
+		op := f.doc.Paths[c.path][c.method]
+		id := strings.TrimSuffix(op.OperationID, "Deprecated")
+		c.cookie = f.cookieFor(op)
+		if setup := setups[id]; setup != nil {
+			setup(f, t, c)
+		}
+		rec := f.doAt(t, strings.ToUpper(c.method), c.path, f.path(c.path, c.params), c.cookie, f.exampleBody(op, c.fields))
+		if _, documented := op.Responses[strconv.Itoa(rec.Code)]; !documented || rec.Code >= 400 {
+			t.Errorf("%s %s (%s): got %d, want a documented success: %s", strings.ToUpper(c.method), c.path, op.OperationID, rec.Code, rec.Body)
+		}
+	}
+}
+
+// phase - этап, на котором вызывается операция
+func (f *fixture) phase(c *call) int {
+	id := f.doc.Paths[c.path][c.method].OperationID
+	switch {
+	case c.method == "delete":
+		return 3
+	case c.method == "get" || strings.HasPrefix(id, "restore"):
+		return 2
+	case c.method == "put" || c.method == "patch":
+		return 1
+	}
+	return 0
+}
+
+// TestSpecReferences проверяет целостность документа: уникальные operationId,
+// разрешимые $ref и описанные параметры пути
+func TestSpecReferences(t *testing.T) {
+	doc := Spec()
+	ids := map[string]bool{}
+	for path, ops := range doc.Paths {
+		for method, op := range ops {
+			if ids[op.OperationID] {
+				t.Errorf("%s %s: duplicate operationId %s", method, path, op.OperationID)
+			}
+			ids[op.OperationID] = true
+			for _, m := range pathParam.FindAllStringSubmatch(path, -1) {
+				if !slices.ContainsFunc(op.Parameters, func(p Parameter) bool { return p.In == "path" && p.Name == m[1] }) {
+					t.Errorf("%s %s: path parameter %s is not described", method, path, m[1])
+				}
+			}
+		}
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ref := range refPattern.FindAllStringSubmatch(string(data), -1) {
+		if _, ok := doc.Components.Schemas[ref[1]]; !ok {
+			t.Errorf("unresolved $ref %s", ref[1])
+		}
+	}
+}
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	// Обработчики хранят данные в JSON-файлах рабочего каталога
+	t.Chdir(t.TempDir())
+	t.Setenv(storage.SigningKeyEnv, strings.Repeat("k", 32))
+	if err := storage.LoadSigningKey(); err != nil {
+		t.Fatal(err)
+	}
+
+	f := &fixture{doc: Spec(), mux: http.NewServeMux(), params: map[string]string{"number": "1", "kind": "resume"}}
+	Register(f.mux)
+
+	f.employer = f.signUp(t, "employer1", "employer")
+	f.candidate = f.signUp(t, "candidate1", "candidate")
+	f.params["employer_id"] = f.userID(t, f.employer)
+
+	f.params["ankety"] = f.newAnkety(t, f.candidate, "2000-01-02")
+	f.params["job"] = f.newJob(t, f.employer)
+	f.params["applications"] = f.create(t, http.MethodPost, Prefix+"/jobs/{id}/applications", f.candidate, nil)
+	return f
+}
+
+// newUser регистрирует нового пользователя с ролью role
+func (f *fixture) newUser(t *testing.T, role string) *http.Cookie {
+	t.Helper()
+	f.seq++
+	return f.signUp(t, role+strconv.Itoa(f.seq+1), role)
+}
+
+// newAnkety создает анкету пользователя и возвращает ее ID
+func (f *fixture) newAnkety(t *testing.T, cookie *http.Cookie, birthDate string) string {
+	t.Helper()
+	return f.create(t, http.MethodPost, Prefix+"/ankety", cookie, map[string]any{
+		"name": "Candidate", "gender": "female", "birth_date": birthDate, "job": "Developer", "school": "School",
+		"skills": []map[string]any{{"name": "Go", "level": "advanced"}},
+	})
+}
+
+// newJob создает вакансию пользователя и возвращает ее ID
+func (f *fixture) newJob(t *testing.T, cookie *http.Cookie) string {
+	t.Helper()
+	f.seq++
+	return f.create(t, http.MethodPost, Prefix+"/jobs", cookie, map[string]any{
+		"title": fmt.Sprintf("Go developer %d", f.seq), "description": "Backend services in Go", "skills": "Go", "job_type": "full",
+	})
+}
+
+func (f *fixture) signUp(t *testing.T, username, role string) *http.Cookie {
+	t.Helper()
+	rec := f.do(t, http.MethodPost, Prefix+"/users", nil, map[string]any{
+		"username": username, "usermail": username + "@example.com", "password": "password1", "role": role,
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("sign up %s: %d %s", username, rec.Code, rec.Body)
+	}
+	rec = f.do(t, http.MethodPost, Prefix+"/sessions", nil, map[string]any{"username": username, "password": "password1"})
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == "auth_token" {
+			return c
+		}
+	}
+	t.Fatalf("log in %s: %d %s", username, rec.Code, rec.Body)
+	return nil
+}
+
+// userID возвращает ID пользователя из его токена
+func (f *fixture) userID(t *testing.T, cookie *http.Cookie) string {
+	t.Helper()
+	userID, _, err := auth.ValidateJWT(cookie.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return userID
+}
+
+// create вызывает операцию создания и возвращает ID созданного объекта
+func (f *fixture) create(t *testing.T, method, path string, cookie *http.Cookie, body any) string {
+	t.Helper()
+	rec := f.do(t, method, path, cookie, body)
+	var created struct {
+		ID string `json:"id"`
+	}
+	if rec.Code != http.StatusCreated || json.Unmarshal(rec.Body.Bytes(), &created) != nil {
+		t.Fatalf("%s %s: %d %s", method, path, rec.Code, rec.Body)
+	}
+	return created.ID
+}
+
+// cookieFor выбирает пользователя для операции: кандидату принадлежат анкета и отклик
+func (f *fixture) cookieFor(op *Operation) *http.Cookie {
+	id := strings.TrimSuffix(op.OperationID, "Deprecated")
+	if op.Tags[0] == "ankety" || slices.Contains([]string{"applyToJob", "listMyApplications", "getAppliedJob"}, id) {
+		return f.candidate
+	}
+	return f.employer
+}
+
+// path подставляет параметры: {id} - объект ресурса из начала пути.
+// Параметры override заменяют параметры фикстуры.
+func (f *fixture) path(pattern string, override map[string]string) string {
+	params := maps.Clone(f.params)
+	maps.Copy(params, override)
+	rest := strings.TrimPrefix(pattern, Prefix)
+	resource := strings.Split(strings.TrimPrefix(rest, "/"), "/")[0]
+	resource = map[string]string{"jobs": "job", "job": "job", "ankety": "ankety", "applications": "applications"}[resource]
+	return pathParam.ReplaceAllStringFunc(pattern, func(m string) string {
+		name := strings.Trim(m, "{}")
+		if name == "id" {
+			return params[resource]
+		}
+		return params[name]
+	})
+}
+
+// body - тело запроса и его тип
+type body struct {
+	contentType string
+	data        []byte
+}
+
+// exampleBody строит тело по описанию операции: JSON со всеми полями схемы и
+// полями fields или multipart-форму с PDF для резюме и PNG для изображений
+func (f *fixture) exampleBody(op *Operation, fields map[string]any) any {
+	if op.RequestBody == nil {
+		return nil
+	}
+	types := make([]string, 0, len(op.RequestBody.Content))
+	for t := range op.RequestBody.Content {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	contentType := types[len(types)-1]
+	schema := op.RequestBody.Content[contentType].Schema
+	if contentType != "multipart/form-data" {
+		value := f.example(schema)
+		if obj, ok := value.(map[string]any); ok {
+			maps.Copy(obj, fields)
+		}
+		data, _ := json.Marshal(value)
+		return body{contentType, data}
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for field := range f.resolve(schema).Properties {
+		if strings.Contains(op.OperationID, "Resume") {
+			part, _ := mw.CreateFormFile(field, "resume.pdf")
+			pdf := fpdf.New("P", "mm", "A4", "")
+			pdf.AddPage()
+			pdf.Output(part)
+			continue
+		}
+		part, _ := mw.CreateFormFile(field, "image.png")
+		png.Encode(part, image.NewGray(image.Rect(0, 0, 2, 2)))
+	}
+	mw.Close()
+	return body{mw.FormDataContentType(), buf.Bytes()}
+}
+
+// example возвращает значение, подходящее схеме: поля из exampleValues, первое
+// значение перечисления, а строки - уникальные, чтобы повторные вызовы не
+// считались дубликатами
+func (f *fixture) example(s *Schema) any {
+	s = f.resolve(s)
+	switch s.Type {
+	case "object":
+		obj := map[string]any{}
+		for name, prop := range s.Properties {
+			if value, ok := exampleValues[name]; ok {
+				if value != nil {
+					obj[name] = value
+				}
+				continue
+			}
+			obj[name] = f.example(prop)
+		}
+		return obj
+	case "array":
+		return []any{f.example(s.Items)}
+	case "integer", "number":
+		if s.Minimum != nil {
+			return *s.Minimum
+		}
+		return 1
+	case "boolean":
+		return false
+	case "string":
+		f.seq++
+		switch {
+		case len(s.Enum) > 0:
+			return s.Enum[0]
+		case s.Format == "email":
+			return fmt.Sprintf("user%d@example.com", f.seq)
+		case s.Format == "uri":
+			return "https://example.com"
+		case s.Format == "date":
+			return time.Now().AddDate(0, 1, 0).Format(time.DateOnly)
+		case s.Format == "date-time":
+			return "2030-01-02T15:04:05Z"
+		}
+		value := "example" + strconv.Itoa(f.seq)
+		if s.MinLength != nil && *s.MinLength > len(value) {
+			value += strings.Repeat("e", *s.MinLength-len(value))
+		}
+		return value
+	}
+	return nil
+}
+func (f *fixture) resolve(s *Schema) *Schema {
+	if s.Ref != "" {
+		return f.doc.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+var refPattern = regexp.MustCompile(`"\$ref":"#/components/schemas/([^"]+)"`)
+
+// do выполняет запрос к операции pattern с параметрами фикстуры
+func (f *fixture) do(t *testing.T, method, pattern string, cookie *http.Cookie, payload any) *httptest.ResponseRecorder {
+	t.Helper()
+	return f.doAt(t, method, pattern, f.path(pattern, nil), cookie, payload)
+}
+
+// doAt выполняет запрос к операции pattern по адресу target и проверяет ответ по документу
+func (f *fixture) doAt(t *testing.T, method, pattern, target string, cookie *http.Cookie, payload any) *httptest.ResponseRecorder {
+	t.Helper()
+	var req *http.Request
+	switch p := payload.(type) {
+	case nil:
+		req = httptest.NewRequest(method, target, nil)
+	case body:
+		req = httptest.NewRequest(method, target, bytes.NewReader(p.data))
+		req.Header.Set("Content-Type", p.contentType)
+	default:
+		data, _ := json.Marshal(p)
+		req = httptest.NewRequest(method, target, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	f.mux.ServeHTTP(rec, req)
+
+	op := f.doc.Paths[pattern][strings.ToLower(method)]
+	if op == nil {
+		t.Fatalf("%s %s is not in the specification", method, pattern)
+	}
+	for _, problem := range f.check(op, rec, payload != nil) {
+		t.Errorf("%s %s: %s", method, pattern, problem)
+	}
+	return rec
+}
+
+// check сравнивает ответ с описанием операции и возвращает расхождения
+func (f *fixture) check(op *Operation, rec *httptest.ResponseRecorder, sentBody bool) []string {
+	contentType := rec.Header().Get("Content-Type")
+	if contentType == "" && rec.Body.Len() > 0 {
+		// Так тип определил бы http.Server при первой записи тела
+		contentType = http.DetectContentType(rec.Body.Bytes())
+	}
+	if (rec.Code == http.StatusNotFound || rec.Code == http.StatusMethodNotAllowed) && strings.HasPrefix(contentType, "text/plain") {
+		return []string{"route is not registered: " + strings.TrimSpace(rec.Body.String())}
+	}
+
+	if strings.HasPrefix(contentType, "application/problem+json") {
+		var p struct {
+			Code   string `json:"code"`
+			Errors []struct {
+				Field   string `json:"field"`
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &p)
+		var issues []string
+		switch p.Code {
+		case "method_not_allowed":
+			issues = append(issues, "handler does not accept the documented method")
+		case "unsupported_media_type":
+			if sentBody {
+				issues = append(issues, "handler does not accept the documented content type")
+			}
+		case "missing_file":
+			if sentBody {
+				issues = append(issues, "handler does not read the documented file field")
+			}
+		}
+		for _, e := range p.Errors {
+			if e.Message == "unknown field" || slices.Contains(typeErrors, e.Message) {
+				issues = append(issues, "request field "+e.Field+": "+e.Message)
+			}
+		}
+		return issues
+	}
+
+	if rec.Code >= 400 {
+		return []string{"error response is not problem+json: " + contentType}
+	}
+	resp, ok := op.Responses[strconv.Itoa(rec.Code)]
+	if !ok {
+		return []string{"undocumented status " + strconv.Itoa(rec.Code)}
+	}
+	if resp.Headers["Location"].Schema != nil && rec.Header().Get("Location") == "" {
+		return []string{"missing Location header"}
+	}
+	if len(resp.Content) == 0 {
+		if rec.Body.Len() > 0 && rec.Code != http.StatusFound {
+			return []string{"undocumented response body"}
+		}
+		return nil
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	media, ok := resp.Content[mediaType]
+	if !ok {
+		return []string{"undocumented content type " + contentType}
+	}
+	if mediaType != "application/json" {
+		return nil
+	}
+	var value any
+	if err := json.Unmarshal(rec.Body.Bytes(), &value); err != nil {
+		return []string{"invalid JSON: " + err.Error()}
+	}
+	return f.conform(media.Schema, value, "body")
+}
+
+// conform проверяет значение по схеме: тип, обязательные и недокументированные поля
+func (f *fixture) conform(s *Schema, value any, at string) []string {
+	s = f.resolve(s)
+	if s.Type == "" {
+		// Произвольное значение, например json.RawMessage
+		return nil
+	}
+	if value == nil {
+		if s.Nullable {
+			return nil
+		}
+		return []string{at + ": null, want " + s.Type}
+	}
+	var issues []string
+	switch v := value.(type) {
+	case map[string]any:
+		if s.Type != "object" {
+			return []string{at + ": object, want " + s.Type}
+		}
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				issues = append(issues, at+"."+name+": required field is missing")
+			}
+		}
+		for name, item := range v {
+			prop, ok := s.Properties[name]
+			if !ok {
+				prop = s.AdditionalProperties
+			}
+			if prop == nil {
+				issues = append(issues, at+"."+name+": undocumented field")
+				continue
+			}
+			issues = append(issues, f.conform(prop, item, at+"."+name)...)
+		}
+	case []any:
+		if s.Type != "array" {
+			return []string{at + ": array, want " + s.Type}
+		}
+		for i, item := range v {
+			issues = append(issues, f.conform(s.Items, item, at+"["+strconv.Itoa(i)+"]")...)
+		}
+	case string:
+		if s.Type != "string" {
+			issues = append(issues, at+": string, want "+s.Type)
+		}
+	case bool:
+		if s.Type != "boolean" {
+			issues = append(issues, at+": boolean, want "+s.Type)
+		}
+	case float64:
+		if s.Type != "number" && (s.Type != "integer" || v != float64(int64(v))) {
+			issues = append(issues, at+": number, want "+s.Type)
+		}
+	}
+	return issues
+}
@@ -0,0 +1,404 @@
+package api
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"talant/problem"
+	"time"
+)
+
+// Document - документ OpenAPI 3. Строится по таблице routes и типам тел запросов
+// и ответов; ограничения полей берутся из тегов validate, см. request.Validate.
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Tags       []Tag                            `json:"tags"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type string `json:"type"`
+	In   string `json:"in"`
+	Name string `json:"name"`
+}
+
+// Schema - JSON Schema в диалекте OpenAPI 3.0
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Maximum              *int               `json:"maximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+const cookieAuth = "cookieAuth"
+
+// Spec строит документ OpenAPI для маршрутов /api/v1 и их устаревших адресов
+func Spec() Document {
+	g := generator{schemas: map[string]*Schema{}, rules: map[reflect.Type]map[string]string{}}
+	doc := Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:   "Talant API",
+			Version: "1.0.0",
+			Description: "Request bodies are also accepted as application/x-www-form-urlencoded or multipart/form-data; " +
+				"lists and objects are then sent as JSON strings. Errors are application/problem+json (RFC 7807).",
+		},
+		Paths: map[string]map[string]*Operation{},
+		Components: Components{
+			Schemas:         g.schemas,
+			SecuritySchemes: map[string]SecurityScheme{cookieAuth: {Type: "apiKey", In: "cookie", Name: "auth_token"}},
+		},
+	}
+	// Правила нужны до построения первой схемы: тип тела может встретиться
+	// раньше, внутри тела другого маршрута, как job.Input в импорте
+	for _, rt := range routes {
+		if rt.Body != nil && rt.Rules != nil {
+			g.rules[reflect.TypeOf(rt.Body)] = fieldRules(reflect.TypeOf(rt.Rules))
+		}
+	}
+	seen := map[string]bool{}
+	for _, rt := range routes {
+		if !seen[rt.Tag] {
+			doc.Tags = append(doc.Tags, Tag{Name: rt.Tag})
+			seen[rt.Tag] = true
+		}
+		op := g.operation(rt)
+		doc.add(rt.Method, Prefix+rt.Path, op)
+
+		if rt.Legacy != "" {
+			method, path := legacyPattern(rt)
+			old := *op
+			old.OperationID = rt.ID + "Deprecated"
+			old.Deprecated = true
+			old.Description = "Use " + rt.Method + " " + Prefix + rt.Path + " instead."
+			doc.add(method, path, &old)
+		}
+	}
+	return doc
+}
+
+func (d *Document) add(method, path string, op *Operation) {
+	if d.Paths[path] == nil {
+		d.Paths[path] = map[string]*Operation{}
+	}
+	d.Paths[path][strings.ToLower(method)] = op
+}
+
+var specJSON = sync.OnceValues(func() ([]byte, error) {
+	return json.MarshalIndent(Spec(), "", "  ")
+})
+
+// SpecHandler отдает документ OpenAPI (GET /api/v1/openapi.json)
+func SpecHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	data, err := specJSON()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error encoding specification")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// generator строит схемы по типам Go; именованные структуры попадают в components
+type generator struct {
+	schemas map[string]*Schema
+	// rules - правила validate для полей типа тела из Route.Rules
+	rules map[reflect.Type]map[string]string
+}
+
+func (g *generator) operation(rt Route) *Operation {
+	op := &Operation{
+		OperationID: rt.ID,
+		Summary:     rt.Summary,
+		Tags:        []string{rt.Tag},
+		Responses:   map[string]Response{},
+	}
+	for _, m := range pathParam.FindAllStringSubmatch(rt.Path, -1) {
+		op.Parameters = append(op.Parameters, Parameter{Name: m[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	for _, q := range rt.Query {
+		op.Parameters = append(op.Parameters, Parameter{Name: q.Name, In: "query", Description: q.Description, Schema: &Schema{Type: "string"}})
+	}
+	if rt.Auth {
+		op.Security = []map[string][]string{{cookieAuth: {}}}
+	}
+
+	switch {
+	case rt.Body != nil:
+		schema := g.schemaOf(reflect.TypeOf(rt.Body))
+		types := []string{"application/json"}
+		if rt.MergePatch {
+			types = []string{"application/merge-patch+json", "application/json"}
+		}
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{}}
+		for _, t := range types {
+			op.RequestBody.Content[t] = MediaType{Schema: schema}
+		}
+	case rt.Upload != "":
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+			"multipart/form-data": {Schema: &Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{rt.Upload: {Type: "string", Format: "binary"}},
+				Required:   []string{rt.Upload},
+			}},
+		}}
+	}
+
+	op.Responses[strconv.Itoa(rt.Status)] = g.response(rt.Status, rt.Response)
+	op.Responses["default"] = Response{
+		Description: "Error",
+		Content: map[string]MediaType{
+			"application/problem+json": {Schema: g.schemaOf(reflect.TypeOf(problem.Problem{}))},
+		},
+	}
+	return op
+}
+
+func (g *generator) response(status int, body any) Response {
+	resp := Response{Description: http.StatusText(status)}
+	switch b := body.(type) {
+	case nil:
+	case Text:
+		resp.Content = map[string]MediaType{"text/plain": {Schema: &Schema{Type: "string"}}}
+	case Redirect:
+		resp.Headers = map[string]Header{"Location": {Description: "Temporary signed link to the file", Schema: &Schema{Type: "string"}}}
+	case Binary:
+		resp.Content = map[string]MediaType{}
+		for _, t := range b.Types {
+			resp.Content[t] = MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+		}
+	default:
+		resp.Content = map[string]MediaType{"application/json": {Schema: g.schemaOf(reflect.TypeOf(body))}}
+	}
+	return resp
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	rawType  = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaOf описывает тип так, как его кодирует encoding/json
+func (g *generator) schemaOf(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawType:
+		// Произвольный JSON
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := g.schemaOf(t.Elem())
+		if s.Ref == "" {
+			s.Nullable = true
+		}
+		return s
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := schemaName(t)
+		if _, ok := g.schemas[name]; !ok {
+			// Заглушка до построения: структура может ссылаться на себя
+			g.schemas[name] = &Schema{}
+			*g.schemas[name] = *g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	return &Schema{}
+}
+
+// schemaName - имя схемы в components: пакет и тип, например "job.Job"
+func schemaName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	return pkg + "." + t.Name()
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			// Встроенная структура: encoding/json поднимает ее поля на уровень выше
+			embedded := g.structSchema(f.Type)
+			for k, v := range embedded.Properties {
+				s.Properties[k] = v
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fs := g.schemaOf(f.Type)
+		rules := f.Tag.Get("validate")
+		if model := g.rules[t][name]; model != "" {
+			rules = strings.Trim(rules+","+model, ",")
+		}
+		if applyRules(fs, rules) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = fs
+	}
+	return s
+}
+
+// fieldRules собирает теги validate полей структуры по именам JSON; поля
+// встроенных структур и структур с правилом inline поднимаются на верхний уровень,
+// как их ошибки в request.Validate
+func fieldRules(t reflect.Type) map[string]string {
+	rules := map[string]string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		tag := f.Tag.Get("validate")
+		if f.Type.Kind() == reflect.Struct && (f.Anonymous || slices.Contains(strings.Split(tag, ","), "inline")) {
+			maps.Copy(rules, fieldRules(f.Type))
+			continue
+		}
+		if name != "" && name != "-" && tag != "" {
+			rules[name] = tag
+		}
+	}
+	return rules
+}
+
+// applyRules переносит правила тега validate в ограничения схемы.
+// Возвращает true, если поле обязательное.
+func applyRules(s *Schema, rules string) bool {
+	if s.Ref != "" || rules == "" {
+		return false
+	}
+	required := false
+	for _, rule := range strings.Split(rules, ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		n, _ := strconv.Atoi(arg)
+		switch name {
+		case "required":
+			required = true
+		case "min", "max":
+			bound := map[string]map[string]**int{
+				"string":  {"min": &s.MinLength, "max": &s.MaxLength},
+				"integer": {"min": &s.Minimum, "max": &s.Maximum},
+				"array":   {"min": &s.MinItems, "max": &s.MaxItems},
+			}[s.Type][name]
+			if bound != nil {
+				*bound = &n
+			}
+		case "oneof":
+			s.Enum = strings.Fields(arg)
+		case "email":
+			s.Format = "email"
+		case "url":
+			s.Format = "uri"
+		case "date":
+			s.Format = "date"
+		}
+	}
+	return required
+}
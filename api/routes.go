@@ -0,0 +1,146 @@
+package api
+
+import (
+	"net/http"
+	"talant/ankety"
+	"talant/application"
+	"talant/auth"
+	"talant/job"
+	"talant/revision"
+	"talant/storage"
+)
+
+var diffQuery = []Param{
+	{"from", "First revision number; defaults to the one before to"},
+	{"to", "Second revision number; defaults to the latest"},
+}
+
+// routes - маршруты /api/v1. Порядок задает порядок операций в документе OpenAPI.
+var routes = []Route{
+	// Пользователи и сессии
+	{Method: http.MethodPost, Path: "/users", Handler: auth.SingInHandler,
+		ID: "signUp", Summary: "Register a candidate or employer", Tag: "users",
+		Body: auth.SignUpInput{}, Status: http.StatusCreated, Response: Text{}, Legacy: "/singin"},
+	{Method: http.MethodPost, Path: "/sessions", Handler: auth.LoaginHandler,
+		ID: "logIn", Summary: "Log in by username or email and set the auth_token cookie", Tag: "sessions",
+		Body: auth.LoginInput{}, Status: http.StatusOK, Response: Text{}, Legacy: "/login"},
+	{Method: http.MethodGet, Path: "/sessions/current", Handler: auth.CheckAuthHandler,
+		ID: "getSession", Summary: "Username of the logged-in user", Tag: "sessions",
+		Auth: true, Status: http.StatusOK, Response: Text{}, Legacy: "/checkauth"},
+	{Method: http.MethodDelete, Path: "/sessions/current", Handler: auth.LogOutHandler,
+		ID: "logOut", Summary: "Log out and clear the auth_token cookie", Tag: "sessions",
+		Status: http.StatusOK, Response: Text{}, Legacy: "/logout"},
+
+	// Вакансии
+	{Method: http.MethodGet, Path: "/jobs", Handler: job.GetAllHandler,
+		ID: "listJobs", Summary: "Published jobs, featured first", Tag: "jobs",
+		Status: http.StatusOK, Response: []job.Job{}, Legacy: "GET /showjobs"},
+	{Method: http.MethodPost, Path: "/jobs", Handler: job.CreateHandler,
+		ID: "createJob", Summary: "Create a job", Tag: "jobs",
+		Auth: true, Body: job.Input{}, Rules: job.Job{}, Status: http.StatusCreated, Response: job.Job{}, Legacy: "POST /createjob"},
+	{Method: http.MethodPost, Path: "/jobs/import", Handler: job.ImportHandler,
+		ID: "importJobs", Summary: "Create up to 100 jobs at once; none are created if any is invalid", Tag: "jobs",
+		Auth: true, Body: job.ImportInput{}, Status: http.StatusCreated, Response: job.ImportResult{}},
+	{Method: http.MethodGet, Path: "/jobs/me", Handler: job.MyjobHandler,
+		ID: "listMyJobs", Summary: "Jobs managed by the current user and their companies", Tag: "jobs",
+		Auth: true, Status: http.StatusOK, Response: []job.Job{}, Legacy: "GET /myjobs"},
+	{Method: http.MethodGet, Path: "/jobs/{id}", Handler: job.OpenHandler,
+		ID: "getJob", Summary: "Get a job; the ETag header carries its version", Tag: "jobs",
+		Status: http.StatusOK, Response: job.Job{}, Legacy: "GET /job/{id}"},
+	{Method: http.MethodPut, Path: "/jobs/{id}", Handler: job.UpdateHandler,
+		ID: "replaceJob", Summary: "Replace a job; honours If-Match", Tag: "jobs",
+		Auth: true, Body: job.Input{}, Rules: job.Job{}, Status: http.StatusOK, Response: Text{}, Legacy: "PUT /job/{id}"},
+	{Method: http.MethodPatch, Path: "/jobs/{id}", Handler: job.PatchHandler,
+		ID: "patchJob", Summary: "Change job fields with a merge patch; honours If-Match", Tag: "jobs",
+		Auth: true, Body: job.Input{}, Rules: job.Job{}, MergePatch: true, Status: http.StatusOK, Response: job.Job{}, Legacy: "PATCH /job/{id}"},
+	{Method: http.MethodDelete, Path: "/jobs/{id}", Handler: job.DeleteHandler,
+		ID: "deleteJob", Summary: "Move a job to the trash; honours If-Match", Tag: "jobs",
+		Auth: true, Status: http.StatusNoContent, Legacy: "DELETE /job/{id}"},
+	{Method: http.MethodGet, Path: "/jobs/{id}/revisions", Handler: job.RevisionsHandler,
+		ID: "listJobRevisions", Summary: "Edit history of a job, newest first", Tag: "jobs",
+		Auth: true, Status: http.StatusOK, Response: []revision.Revision{}, Legacy: "GET /job/{id}/revisions"},
+	{Method: http.MethodGet, Path: "/jobs/{id}/diff", Handler: job.DiffHandler,
+		ID: "diffJobRevisions", Summary: "Field changes between two job revisions", Tag: "jobs",
+		Auth: true, Query: diffQuery, Status: http.StatusOK, Response: revision.DiffResult{}, Legacy: "GET /job/{id}/diff"},
+	{Method: http.MethodPost, Path: "/jobs/{id}/revisions/{number}/restore", Handler: job.RestoreHandler,
+		ID: "restoreJobRevision", Summary: "Restore job content from a revision", Tag: "jobs",
+		Auth: true, Status: http.StatusOK, Response: job.Job{}, Legacy: "POST /job/{id}/revisions/{number}/restore"},
+	{Method: http.MethodPost, Path: "/jobs/{id}/attachments/logo", Handler: job.UploadLogoHandler,
+		ID: "uploadJobLogo", Summary: "Upload a job logo image", Tag: "jobs",
+		Auth: true, Upload: "file", Status: http.StatusCreated, Response: storage.File{}, Legacy: "POST /job/{id}/attachments/logo"},
+	{Method: http.MethodGet, Path: "/jobs/{id}/attachments/logo", Handler: job.LogoHandler,
+		ID: "getJobLogo", Summary: "Redirect to a temporary link to the job logo", Tag: "jobs",
+		Query:  []Param{{"thumb", "Any value - link to the thumbnail"}},
+		Status: http.StatusFound, Response: Redirect{}, Legacy: "GET /job/{id}/attachments/logo"},
+	{Method: http.MethodPost, Path: "/jobs/{id}/applications", Handler: job.ApplyHandler,
+		ID: "applyToJob", Summary: "Apply to a job with the current user's ankety", Tag: "jobs",
+		Auth: true, Status: http.StatusCreated, Response: application.Application{}, Legacy: "POST /job/{id}/apply"},
+	{Method: http.MethodGet, Path: "/jobs/{id}/applications", Handler: application.ForJobHandler,
+		ID: "listJobApplications", Summary: "Applications to a job, for its managers", Tag: "jobs",
+		Auth: true, Status: http.StatusOK, Response: []application.Application{}, Legacy: "GET /job/{id}/applications"},
+
+	// Анкеты
+	{Method: http.MethodGet, Path: "/ankety", Handler: ankety.ShowAnketyHandler,
+		ID: "listAnkety", Summary: "Ankety visible to the caller, contacts masked", Tag: "ankety",
+		Status: http.StatusOK, Response: []ankety.Ankety{}, Legacy: "/showankety"},
+	{Method: http.MethodPost, Path: "/ankety", Handler: ankety.CreateHandler,
+		ID: "createAnkety", Summary: "Create the current user's ankety", Tag: "ankety",
+		Auth: true, Body: ankety.Input{}, Rules: ankety.Ankety{}, Status: http.StatusCreated, Response: ankety.Ankety{}, Legacy: "/createankety"},
+	{Method: http.MethodGet, Path: "/ankety/me", Handler: ankety.MyHandler,
+		ID: "getMyAnkety", Summary: "The current user's ankety", Tag: "ankety",
+		Auth: true, Status: http.StatusOK, Response: ankety.Ankety{}, Legacy: "GET /ankety/me"},
+	{Method: http.MethodGet, Path: "/ankety/{id}", Handler: ankety.OpenHandler,
+		ID: "getAnkety", Summary: "Get an ankety if its visibility allows", Tag: "ankety",
+		Status: http.StatusOK, Response: ankety.Ankety{}, Legacy: "GET /ankety/{id}"},
+	{Method: http.MethodPut, Path: "/ankety/{id}", Handler: ankety.UpdateHandler,
+		ID: "replaceAnkety", Summary: "Replace all ankety fields", Tag: "ankety",
+		Auth: true, Body: ankety.Input{}, Rules: ankety.Ankety{}, Status: http.StatusOK, Response: ankety.Ankety{}, Legacy: "PUT /ankety/{id}"},
+	{Method: http.MethodPatch, Path: "/ankety/{id}", Handler: ankety.UpdateHandler,
+		ID: "updateAnkety", Summary: "Change only the sent ankety fields", Tag: "ankety",
+		Auth: true, Body: ankety.Input{}, Rules: ankety.Ankety{}, Status: http.StatusOK, Response: ankety.Ankety{}, Legacy: "PATCH /ankety/{id}"},
+	{Method: http.MethodDelete, Path: "/ankety/{id}", Handler: ankety.DeleteHandler,
+		ID: "deleteAnkety", Summary: "Move an ankety to the trash", Tag: "ankety",
+		Auth: true, Status: http.StatusNoContent, Legacy: "DELETE /ankety/{id}"},
+	{Method: http.MethodGet, Path: "/ankety/{id}/revisions", Handler: ankety.RevisionsHandler,
+		ID: "listAnketyRevisions", Summary: "Edit history of an ankety, newest first", Tag: "ankety",
+		Auth: true, Status: http.StatusOK, Response: []revision.Revision{}, Legacy: "GET /ankety/{id}/revisions"},
+	{Method: http.MethodGet, Path: "/ankety/{id}/diff", Handler: ankety.DiffHandler,
+		ID: "diffAnketyRevisions", Summary: "Field changes between two ankety revisions", Tag: "ankety",
+		Auth: true, Query: diffQuery, Status: http.StatusOK, Response: revision.DiffResult{}, Legacy: "GET /ankety/{id}/diff"},
+	{Method: http.MethodPost, Path: "/ankety/{id}/revisions/{number}/restore", Handler: ankety.RestoreHandler,
+		ID: "restoreAnketyRevision", Summary: "Restore ankety content from a revision", Tag: "ankety",
+		Auth: true, Status: http.StatusOK, Response: ankety.Ankety{}, Legacy: "POST /ankety/{id}/revisions/{number}/restore"},
+	{Method: http.MethodPost, Path: "/ankety/{id}/attachments/resume", Handler: ankety.UploadResumeHandler,
+		ID: "uploadResume", Summary: "Upload a PDF resume", Tag: "ankety",
+		Auth: true, Upload: "file", Status: http.StatusCreated, Response: storage.File{}, Legacy: "POST /ankety/{id}/attachments/resume"},
+	{Method: http.MethodPost, Path: "/ankety/{id}/attachments/photo", Handler: ankety.UploadPhotoHandler,
+		ID: "uploadPhoto", Summary: "Upload a candidate photo", Tag: "ankety",
+		Auth: true, Upload: "file", Status: http.StatusCreated, Response: storage.File{}, Legacy: "POST /ankety/{id}/attachments/photo"},
+	{Method: http.MethodGet, Path: "/ankety/{id}/attachments/{kind}", Handler: ankety.AttachmentHandler,
+		ID: "getAnketyAttachment", Summary: "Redirect to a temporary link to resume, photo or photo-thumb", Tag: "ankety",
+		Auth: true, Status: http.StatusFound, Response: Redirect{}, Legacy: "GET /ankety/{id}/attachments/{kind}"},
+	{Method: http.MethodGet, Path: "/ankety/{id}/resume", Handler: ankety.ResumeHandler,
+		ID: "renderResume", Summary: "Render the ankety as a resume", Tag: "ankety",
+		Auth: true, Query: []Param{{"template", "Resume template name"}, {"format", "html (default) or pdf"}},
+		Status: http.StatusOK, Response: Binary{Types: []string{"text/html", "application/pdf"}}, Legacy: "GET /ankety/{id}/resume"},
+	{Method: http.MethodPost, Path: "/ankety/{id}/consent", Handler: ankety.ConsentHandler,
+		ID: "shareContacts", Summary: "Show contacts to an employer; returns employer IDs", Tag: "ankety",
+		Auth: true, Body: ankety.ConsentInput{}, Status: http.StatusOK, Response: []string{}, Legacy: "POST /ankety/{id}/consent"},
+	{Method: http.MethodDelete, Path: "/ankety/{id}/consent/{employer_id}", Handler: ankety.ConsentHandler,
+		ID: "unshareContacts", Summary: "Hide contacts from an employer; returns employer IDs", Tag: "ankety",
+		Auth: true, Status: http.StatusOK, Response: []string{}, Legacy: "DELETE /ankety/{id}/consent/{employer_id}"},
+	{Method: http.MethodPost, Path: "/ankety/{id}/guardian-consent", Handler: ankety.GuardianConsentHandler,
+		ID: "giveGuardianConsent", Summary: "Record guardian consent for a minor", Tag: "ankety",
+		Auth: true, Body: ankety.GuardianInput{}, Status: http.StatusCreated, Response: ankety.GuardianConsent{}, Legacy: "POST /ankety/{id}/guardian-consent"},
+
+	// Отклики
+	{Method: http.MethodGet, Path: "/applications/me", Handler: application.MyHandler,
+		ID: "listMyApplications", Summary: "Applications of the current candidate", Tag: "applications",
+		Auth: true, Status: http.StatusOK, Response: []application.Application{}, Legacy: "GET /applications/me"},
+	{Method: http.MethodPut, Path: "/applications/{id}/status", Handler: application.StatusHandler,
+		ID: "setApplicationStatus", Summary: "Change application status; the candidate is notified", Tag: "applications",
+		Auth: true, Body: application.StatusInput{}, Status: http.StatusOK, Response: application.Application{}, Legacy: "PUT /applications/{id}/status"},
+	{Method: http.MethodGet, Path: "/applications/{id}/job", Handler: job.AppliedVersionHandler,
+		ID: "getAppliedJob", Summary: "The job as it was when the candidate applied", Tag: "applications",
+		Auth: true, Status: http.StatusOK, Response: job.AppliedVersion{}, Legacy: "GET /applications/{id}/job"},
+}
@@ -0,0 +1,284 @@
+package application
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"talant/auth"
+	"talant/company"
+	"talant/notification"
+	"talant/problem"
+	"talant/realtime"
+	"talant/request"
+	"time"
+)
+
+// Application - отклик кандидата на вакансию
+type Application struct {
+	Id          string `json:"id"`
+	JobID       string `json:"job_id"`
+	CandidateID string `json:"candidate_id"`
+	// EmployerID - автор вакансии на момент отклика
+	EmployerID string    `json:"employer_id"`
+	AnketyID   string    `json:"ankety_id"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+	// CompanyID - компания вакансии: ее команда ведет отклик наравне с автором.
+	// Поддерживается в актуальном состоянии через SetCompany
+	CompanyID string `json:"company_id,omitempty"`
+	// JobRevision - ревизия вакансии на момент отклика, см. job.AppliedVersionHandler
+	JobRevision int `json:"job_revision,omitempty"`
+}
+
+const StatusApplied = "applied"
+
+var applicationsFile string = "applications.json"
+
+// mu сериализует изменения applications.json
+var mu sync.Mutex
+
+var ErrAlreadyApplied = errors.New("already applied to this job")
+
+func Load() ([]Application, error) {
+	data, err := os.ReadFile(applicationsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Application{}, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла %s: %w", applicationsFile, err)
+	}
+	if len(data) == 0 {
+		return []Application{}, nil
+	}
+
+	var apps []Application
+	if err := json.Unmarshal(data, &apps); err != nil {
+		return nil, fmt.Errorf("ошибка разбора JSON из файла %s: %w", applicationsFile, err)
+	}
+	return apps, nil
+}
+
+func Save(apps []Application) error {
+	data, err := json.MarshalIndent(apps, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования в JSON: %w", err)
+	}
+	if err := os.WriteFile(applicationsFile, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", applicationsFile, err)
+	}
+	return nil
+}
+
+// Add сохраняет новый отклик, если кандидат еще не откликался на эту вакансию
+func Add(app Application) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	apps, err := Load()
+	if err != nil {
+		return err
+	}
+	for _, a := range apps {
+		if a.JobID == app.JobID && a.CandidateID == app.CandidateID {
+			return ErrAlreadyApplied
+		}
+	}
+	return Save(append(apps, app))
+}
+
+// ManagedBy сообщает, может ли пользователь вести отклик со стороны работодателя:
+// это автор вакансии и участники команды ее компании
+func (a Application) ManagedBy(userID string) bool {
+	return a.EmployerID == userID || company.IsMember(a.CompanyID, userID)
+}
+
+// SetCompany переносит отклики на вакансию за ее новой компанией
+func SetCompany(jobID, companyID string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	apps, err := Load()
+	if err != nil {
+		return err
+	}
+	changed := false
+	for i := range apps {
+		if apps[i].JobID == jobID && apps[i].CompanyID != companyID {
+			apps[i].CompanyID = companyID
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return Save(apps)
+}
+
+// Get возвращает отклик по ID или nil, если его нет
+func Get(id string) (*Application, error) {
+	apps, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	for i := range apps {
+		if apps[i].Id == id {
+			return &apps[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// HasApplied сообщает, откликался ли кандидат на какую-либо вакансию работодателя
+// или компании, где он рекрутер, - по тому же правилу, что и доступ к отклику, см. ManagedBy
+func HasApplied(candidateID, employerID string) bool {
+	apps, err := Load()
+	if err != nil {
+		return false
+	}
+	for _, a := range apps {
+		if a.CandidateID == candidateID && a.ManagedBy(employerID) {
+			return true
+		}
+	}
+	return false
+}
+
+// MyHandler возвращает отклики текущего кандидата
+func MyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	apps, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading applications")
+		return
+	}
+
+	mine := []Application{}
+	for _, a := range apps {
+		if a.CandidateID == userID {
+			mine = append(mine, a)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mine)
+}
+
+// ForJobHandler возвращает отклики на вакансию ее автору (/job/{id}/applications)
+func ForJobHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	apps, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading applications")
+		return
+	}
+
+	jobID := r.PathValue("id")
+	result := []Application{}
+	for _, a := range apps {
+		if a.JobID == jobID && a.ManagedBy(userID) {
+			result = append(result, a)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// StatusInput - тело запроса смены статуса; в правиле oneof - статусы,
+// которые может выставить работодатель
+type StatusInput struct {
+	Status string `json:"status" validate:"required,oneof=applied viewed interview offer rejected hired"`
+}
+
+var statusNames = map[string]string{
+	StatusApplied: "отправлен",
+	"viewed":      "просмотрен",
+	"interview":   "приглашение на собеседование",
+	"offer":       "предложение о работе",
+	"rejected":    "отказ",
+	"hired":       "принят на работу",
+}
+
+// StatusHandler меняет статус отклика (PUT /applications/{id}/status).
+// Менять статус может только работодатель, кандидат получает уведомление.
+func StatusHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+	var in StatusInput
+	if _, err := request.Decode(w, r, &in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if err := request.Validate(&in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	status := in.Status
+
+	mu.Lock()
+	defer mu.Unlock()
+	apps, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading applications")
+		return
+	}
+
+	for i := range apps {
+		if apps[i].Id != r.PathValue("id") {
+			continue
+		}
+		if !apps[i].ManagedBy(userID) {
+			problem.Write(w, http.StatusForbidden, problem.CodeForbidden, "Only the employer can change status")
+			return
+		}
+		changed := apps[i].Status != status
+		apps[i].Status = status
+		if err := Save(apps); err != nil {
+			problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving application")
+			return
+		}
+		if changed {
+			realtime.Publish(realtime.TopicApplications, "application.status", apps[i].CandidateID, apps[i])
+			err := notification.Send(apps[i].CandidateID, notification.EventStatusChange,
+				"Статус отклика изменен", "Новый статус: "+statusNames[status], "/applications/me")
+			if err != nil {
+				fmt.Printf("Ошибка отправки уведомления: %v\n", err)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(apps[i])
+		return
+	}
+	problem.Write(w, http.StatusNotFound, problem.CodeApplicationNotFound, "Application not found")
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"talant/problem"
+)
+
+// VerifyHandler отмечает работодателя как проверенного (POST /admin/users/{id}/verify).
+// DELETE снимает отметку. Доступно только администраторам.
+func VerifyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	adminID, err := UserIDFromRequest(r)
+	if err != nil {
+		Error(w, err)
+		return
+	}
+	if RoleOf(adminID) != RoleAdmin {
+		problem.Write(w, http.StatusForbidden, problem.CodeAdminOnly, "Admin only")
+		return
+	}
+
+	err = UpdateUser(r.PathValue("id"), func(u *User) {
+		u.Verified = r.Method == http.MethodPost
+	})
+	if errors.Is(err, ErrUserNotFound) {
+		problem.Write(w, http.StatusNotFound, problem.CodeUserNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -2,9 +2,13 @@ package auth
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"sync"
+	"talant/problem"
+	"talant/request"
 	"time"
 
 	//"strings"
@@ -18,6 +22,39 @@ type User struct {
 	Username string `json:"username"`
 	Usermail string `json:"usermail"`
 	Password string `json:"password"`
+	// Role - "candidate" или "employer", пустое значение у старых записей считается candidate
+	Role string `json:"role,omitempty"`
+	// Verified - работодатель проверен администратором
+	Verified bool `json:"verified,omitempty"`
+	// Plan - тарифный план с лимитами публикаций, пустой - план по умолчанию для роли
+	Plan string `json:"plan,omitempty"`
+	// SuspendedUntil и Banned - ограничения, наложенные администратором по жалобам
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	Banned         bool       `json:"banned,omitempty"`
+}
+
+const (
+	RoleCandidate = "candidate"
+	RoleEmployer  = "employer"
+	RoleAdmin     = "admin"
+	// RoleModerator проверяет вакансии и анкеты в очереди модерации.
+	// Как и администратора, его назначают вручную в data.json.
+	RoleModerator = "moderator"
+)
+
+// SignUpInput - поля запроса регистрации
+type SignUpInput struct {
+	Username string `json:"username" validate:"trim,required,min=3,max=32"`
+	Usermail string `json:"usermail" validate:"trim,required,email,max=254"`
+	// Пароль не обрезается. bcrypt принимает не больше 72 байт, см. ErrPasswordTooLong в SingInHandler
+	Password string `json:"password" validate:"required,min=8,max=72"`
+	Role     string `json:"role" validate:"oneof=candidate employer"`
+}
+
+// LoginInput - поля запроса входа
+type LoginInput struct {
+	Username string `json:"username"` // имя пользователя или email
+	Password string `json:"password"`
 }
 
 type CustomClaims struct {
@@ -27,6 +64,10 @@ type CustomClaims struct {
 }
 
 var dataFile string = "data.json"
+
+// mu сериализует цикл "прочитать - изменить - записать" над dataFile: иначе смена
+// плана, одновременная с блокировкой, может молча отменить блокировку
+var mu sync.Mutex
 var jwtSecretKey = []byte("YOUR_EXTREMELY_STRONG_SECRET_KEY") // Секретный ключ для подписи JWT
 // Middleware для обработки CORS
 func CORSMiddleware(next http.Handler) http.Handler {
@@ -39,8 +80,9 @@ func CORSMiddleware(next http.Handler) http.Handler {
 		}
 		// Помечаем, что ответ зависит от Origin, чтобы кэширующие прокси не мешали
 		w.Header().Set("Vary", "Origin")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, X-Request-ID, Deprecation, Link")
 		// Разрешаем отправлять cookie/credentials
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 
@@ -57,26 +99,28 @@ func CORSMiddleware(next http.Handler) http.Handler {
 
 func CheckAuthHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
 	cookie, err := r.Cookie("auth_token")
 	if err != nil {
-		http.Error(w, "Unauthorized: missing token", http.StatusUnauthorized)
+		problem.Write(w, http.StatusUnauthorized, problem.CodeUnauthorized, "Missing token")
 		return
 	}
 	_, username, err := ValidateJWT(cookie.Value)
 	if err != nil {
-		http.Error(w, "Unauthorized: invalid token", http.StatusUnauthorized)
+		problem.Write(w, http.StatusUnauthorized, problem.CodeUnauthorized, "Invalid token")
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(username))
 }
+
+// LogOutHandler завершает сессию (POST /logout, DELETE /api/v1/sessions/current)
 func LogOutHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 	expiredCookie := http.Cookie{
@@ -88,13 +132,14 @@ func LogOutHandler(w http.ResponseWriter, r *http.Request) {
 		HttpOnly: true,                       // Важно: HttpOnly должен быть true
 		Secure:   false,                      // Используйте 'true', если работаете по HTTPS
 	}
+	// id_cookie больше не выдается: пользователь определяется только по JWT.
+	// Удаляем куку, оставшуюся у браузеров со старых входов.
 	http.SetCookie(w, &http.Cookie{
-		Name:     "id_cookie", // Используем более явное имя
+		Name:     "id_cookie",
 		Value:    "",
-		HttpOnly: true,  // Защита от XSS
-		Secure:   false, // !!! Использовать TRUE для продакшена (HTTPS)
-		Expires:  time.Now().Add(24 * time.Hour),
 		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
 	})
 	http.SetCookie(w, &expiredCookie)
 
@@ -121,6 +166,18 @@ func LoadUser() ([]User, error) {
 	return users, nil
 }
 
+// SaveUsers перезаписывает файл пользователей
+func SaveUsers(users []User) error {
+	data, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования в JSON: %w", err)
+	}
+	if err := os.WriteFile(dataFile, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", dataFile, err)
+	}
+	return nil
+}
+
 // GenerateJWT создает подписанный токен
 func GenerateJWT(userID, username string) (string, error) {
 	// Устанавливаем срок действия (например, 24 часа)
@@ -168,6 +225,121 @@ func ValidateJWT(tokenString string) (string, string, error) {
 	return "", "", fmt.Errorf("invalid token")
 }
 
+// UserIDFromRequest достает ID пользователя из JWT в куке auth_token.
+// Токены заблокированных пользователей не принимаются, даже если еще не истекли.
+func UserIDFromRequest(r *http.Request) (string, error) {
+	cookie, err := r.Cookie("auth_token")
+	if err != nil {
+		return "", fmt.Errorf("missing token")
+	}
+	userID, _, err := ValidateJWT(cookie.Value)
+	if err != nil {
+		return "", fmt.Errorf("invalid token")
+	}
+	if user, err := GetUser(userID); err == nil && user != nil {
+		if err := user.Restriction(time.Now()); err != nil {
+			return "", err
+		}
+	}
+	return userID, nil
+}
+
+var (
+	ErrBanned       = errors.New("account is banned")
+	ErrSuspended    = errors.New("account is suspended")
+	ErrUserNotFound = errors.New("user not found")
+)
+
+// Restriction возвращает ErrBanned или ErrSuspended, если пользователю закрыт доступ
+func (u User) Restriction(now time.Time) error {
+	if u.Banned {
+		return ErrBanned
+	}
+	if u.SuspendedUntil != nil && now.Before(*u.SuspendedUntil) {
+		return fmt.Errorf("%w until %s", ErrSuspended, u.SuspendedUntil.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// restrictionCode - код ошибки для заблокированного или приостановленного аккаунта
+func restrictionCode(err error) string {
+	switch {
+	case errors.Is(err, ErrBanned):
+		return problem.CodeAccountBanned
+	case errors.Is(err, ErrSuspended):
+		return problem.CodeAccountSuspended
+	default:
+		return problem.CodeUnauthorized
+	}
+}
+
+// Error отвечает на ошибку UserIDFromRequest. У блокировки аккаунта свой код,
+// чтобы клиент мог показать причину, а не просить войти заново.
+func Error(w http.ResponseWriter, err error) {
+	problem.Write(w, http.StatusUnauthorized, restrictionCode(err), err.Error())
+}
+
+// Restrict сохраняет ограничения пользователя; until == nil и banned == false снимают их
+func Restrict(userID string, until *time.Time, banned bool) error {
+	return UpdateUser(userID, func(u *User) {
+		u.SuspendedUntil = until
+		u.Banned = banned
+	})
+}
+
+// UpdateUser меняет пользователя функцией change и сохраняет файл пользователей.
+// Все изменения пользователей проходят через mu. Если пользователя нет - ErrUserNotFound.
+func UpdateUser(userID string, change func(*User)) error {
+	mu.Lock()
+	defer mu.Unlock()
+	users, err := LoadUser()
+	if err != nil {
+		return err
+	}
+	for i := range users {
+		if users[i].Id == userID {
+			change(&users[i])
+			return SaveUsers(users)
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrUserNotFound, userID)
+}
+
+// GetUser возвращает пользователя по ID или nil, если его нет
+func GetUser(userID string) (*User, error) {
+	users, err := LoadUser()
+	if err != nil {
+		return nil, err
+	}
+	for i := range users {
+		if users[i].Id == userID {
+			return &users[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// RoleOf возвращает роль пользователя; неизвестный пользователь считается кандидатом
+func RoleOf(userID string) string {
+	user, err := GetUser(userID)
+	if err != nil || user == nil || user.Role == "" {
+		return RoleCandidate
+	}
+	return user.Role
+}
+
+// IsModerator сообщает, может ли пользователь разбирать очередь модерации
+func IsModerator(userID string) bool {
+	role := RoleOf(userID)
+	return role == RoleModerator || role == RoleAdmin
+}
+
+// IsVerified сообщает, проверен ли пользователь администратором
+func IsVerified(userID string) bool {
+	user, err := GetUser(userID)
+	return err == nil && user != nil && user.Verified
+}
+
 // Хеширует пароль и возвращает строку хэша
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -179,19 +351,22 @@ func HashPassword(password string) (string, error) {
 }
 func LoaginHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	var in LoginInput
+	if _, err := request.Decode(w, r, &in); err != nil {
+		request.WriteError(w, err)
 		return
 	}
-	r.ParseForm()
-	usernameOrMail := r.FormValue("username")
-	password := r.FormValue("password")
-	if usernameOrMail == "" || password == "" {
-		http.Error(w, "Missing fields", http.StatusBadRequest)
+	if err := request.Require("username", in.Username, "password", in.Password); err != nil {
+		request.WriteError(w, err)
 		return
 	}
+	usernameOrMail, password := in.Username, in.Password
 	users, err := LoadUser()
 	if err != nil {
-		http.Error(w, "Error loading users", http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading users")
 		return
 	}
 	var authenticatedUser *User
@@ -206,15 +381,18 @@ func LoaginHandler(w http.ResponseWriter, r *http.Request) {
 
 	if authenticatedUser == nil {
 		// Если пользователь не найден ИЛИ пароль был неверен
-		http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+		problem.Write(w, http.StatusUnauthorized, problem.CodeInvalidCredentials, "Invalid username or password")
+		return
+	}
+	if err := authenticatedUser.Restriction(time.Now()); err != nil {
+		problem.Write(w, http.StatusForbidden, restrictionCode(err), err.Error())
 		return
 	}
-	userID := authenticatedUser.Id
 
 	// 2. ГЕНЕРАЦИЯ НОВОГО ТОКЕНА (Правильно!)
 	tokenString, err := GenerateJWT(authenticatedUser.Id, authenticatedUser.Username)
 	if err != nil {
-		http.Error(w, "Error generating token", http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error generating token")
 		return
 	}
 
@@ -227,14 +405,6 @@ func LoaginHandler(w http.ResponseWriter, r *http.Request) {
 		Expires:  time.Now().Add(24 * time.Hour),
 		Path:     "/",
 	})
-	http.SetCookie(w, &http.Cookie{
-		Name:     "id_cookie", // Используем более явное имя
-		Value:    userID,
-		HttpOnly: true,  // Защита от XSS
-		Secure:   false, // !!! Использовать TRUE для продакшена (HTTPS)
-		Expires:  time.Now().Add(24 * time.Hour),
-		Path:     "/",
-	})
 
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("Login successful. New token set."))
@@ -242,55 +412,60 @@ func LoaginHandler(w http.ResponseWriter, r *http.Request) {
 
 func SingInHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
 		return
 	}
-	r.ParseForm()
-	username := r.FormValue("username")
-	usermail := r.FormValue("usermail")
-	password := r.FormValue("password")
-	if username == "" || usermail == "" || password == "" {
-		http.Error(w, "Missing fields", http.StatusBadRequest)
+	var in SignUpInput
+	if _, err := request.Decode(w, r, &in); err != nil {
+		request.WriteError(w, err)
 		return
 	}
+	if err := request.Validate(&in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	username, usermail, password, role := in.Username, in.Usermail, in.Password, in.Role
+	if role == "" {
+		role = RoleCandidate
+	}
 
+	// Пароль хешируется до блокировки: bcrypt медленный
+	hashedPassword, err := HashPassword(password)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		// Правило max считает символы, а не байты
+		request.WriteError(w, request.Invalid("password", "is too long"))
+		return
+	}
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error hashing password")
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
 	users, err := LoadUser()
 	if err != nil {
-		http.Error(w, "Error loading users", http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading users")
 		return
 	}
 	for _, user := range users {
 		if user.Username == username || user.Usermail == usermail {
-			http.Error(w, "Username or email already exists", http.StatusConflict)
+			problem.Write(w, http.StatusConflict, problem.CodeAlreadyExists, "Username or email already exists")
 			return
 		}
 	}
 	//если не найден пользователь:
 
-	id := uuid.New().String()
-	hashedPassword, err := HashPassword(password)
-	if err != nil {
-		http.Error(w, "Error hashing password", http.StatusInternalServerError)
-		return
-	}
-
 	newUser := User{
-		Id:       id,
+		Id:       uuid.New().String(),
 		Username: username,
 		Usermail: usermail,
 		Password: hashedPassword,
+		Role:     role,
 	}
-	users = append(users, newUser)
-	updatedData, err := json.MarshalIndent(users, "", "  ")
-	if err != nil {
-		http.Error(w, "Error encoding data", http.StatusInternalServerError)
-		return
-	}
-
-	err = os.WriteFile(dataFile, updatedData, 0644)
-	if err != nil {
+	if err := SaveUsers(append(users, newUser)); err != nil {
 		// Если запись не удалась, возвращаем ошибку, и прекращаем выполнение
-		http.Error(w, "Error writing data file", http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
 		return
 	}
 
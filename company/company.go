@@ -0,0 +1,132 @@
+package company
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"talant/storage"
+	"time"
+)
+
+// Роли участников компании
+const (
+	RoleOwner     = "owner"     // управляет профилем и командой
+	RoleRecruiter = "recruiter" // ведет вакансии компании
+)
+
+// Member - пользователь в команде компании
+type Member struct {
+	UserID  string    `json:"user_id"`
+	Role    string    `json:"role"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// Company - профиль компании-работодателя
+type Company struct {
+	Id          string        `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Website     string        `json:"website,omitempty"`
+	Logo        *storage.File `json:"logo,omitempty"`
+	// Verified - компания проверена администратором
+	Verified  bool      `json:"verified"`
+	Members   []Member  `json:"members"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+var companiesFile string = "companies.json"
+
+// mu сериализует изменения companies.json
+var mu sync.Mutex
+
+func Load() ([]Company, error) {
+	data, err := os.ReadFile(companiesFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Company{}, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла %s: %w", companiesFile, err)
+	}
+	if len(data) == 0 {
+		return []Company{}, nil
+	}
+
+	var list []Company
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("ошибка разбора JSON из файла %s: %w", companiesFile, err)
+	}
+	return list, nil
+}
+
+func Save(list []Company) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования в JSON: %w", err)
+	}
+	if err := os.WriteFile(companiesFile, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", companiesFile, err)
+	}
+	return nil
+}
+
+// Get возвращает компанию по ID или nil, если ее нет
+func Get(id string) (*Company, error) {
+	list, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if list[i].Id == id {
+			return &list[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// RoleOf возвращает роль пользователя в компании или пустую строку
+func (c Company) RoleOf(userID string) string {
+	for _, m := range c.Members {
+		if m.UserID == userID {
+			return m.Role
+		}
+	}
+	return ""
+}
+
+// owners - число владельцев: последнего владельца нельзя удалить или понизить
+func (c Company) owners() int {
+	n := 0
+	for _, m := range c.Members {
+		if m.Role == RoleOwner {
+			n++
+		}
+	}
+	return n
+}
+
+// IsMember сообщает, состоит ли пользователь в команде компании.
+// Ошибка чтения трактуется как отсутствие прав.
+func IsMember(companyID, userID string) bool {
+	if companyID == "" || userID == "" {
+		return false
+	}
+	c, err := Get(companyID)
+	if err != nil || c == nil {
+		return false
+	}
+	return c.RoleOf(userID) != ""
+}
+
+// FileKeys возвращает ключи логотипов компаний, см. storage.OnReferences
+func FileKeys() ([]string, error) {
+	list, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, c := range list {
+		keys = append(keys, c.Logo.Keys()...)
+	}
+	return keys, nil
+}
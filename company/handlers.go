@@ -0,0 +1,504 @@
+package company
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"talant/auth"
+	"talant/problem"
+	"talant/request"
+	"talant/storage"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProfileInput - поля профиля компании в запросах создания и изменения
+type ProfileInput struct {
+	Name        string `json:"name" validate:"trim"`
+	Description string `json:"description" validate:"trim"`
+	Website     string `json:"website" validate:"trim,url"`
+}
+
+// MemberInput - поля запроса добавления участника: user_id или usermail
+type MemberInput struct {
+	UserID   string `json:"user_id" validate:"trim"`
+	Usermail string `json:"usermail" validate:"trim"`
+	Role     string `json:"role" validate:"oneof=owner recruiter"`
+}
+
+// RoleInput - поле запроса смены роли участника
+type RoleInput struct {
+	Role string `json:"role" validate:"oneof=owner recruiter"`
+}
+
+// decodeProfile читает профиль компании из запроса. При replace=false меняются
+// только переданные поля, поэтому name обязателен, только если передан или replace.
+// При ошибке ответ уже записан.
+func decodeProfile(w http.ResponseWriter, r *http.Request, replace bool) (ProfileInput, request.Fields, bool) {
+	var in ProfileInput
+	sent, err := request.Decode(w, r, &in)
+	if err == nil {
+		err = request.Validate(&in)
+	}
+	if err == nil && in.Name == "" && (replace || sent.Has("name")) {
+		err = request.Invalid("name", "is required")
+	}
+	if err != nil {
+		request.WriteError(w, err)
+		return ProfileInput{}, nil, false
+	}
+	return in, sent, true
+}
+
+// applyProfile переносит в компанию поля профиля: все при replace, иначе только переданные
+func applyProfile(c *Company, in ProfileInput, sent request.Fields, replace bool) {
+	if replace || sent.Has("name") {
+		c.Name = in.Name
+	}
+	if replace || sent.Has("description") {
+		c.Description = in.Description
+	}
+	if replace || sent.Has("website") {
+		c.Website = in.Website
+	}
+}
+
+// nameTaken сообщает, занято ли название другой компанией (без учета регистра)
+func nameTaken(list []Company, name, exceptID string) bool {
+	for _, c := range list {
+		if c.Id != exceptID && strings.EqualFold(c.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateHandler создает компанию, создатель становится ее владельцем (POST /companies)
+func CreateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+	if role := auth.RoleOf(userID); role != auth.RoleEmployer && role != auth.RoleAdmin {
+		problem.Write(w, http.StatusForbidden, problem.CodeForbidden, "Only employers can create companies")
+		return
+	}
+	in, sent, ok := decodeProfile(w, r, true)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	c := Company{
+		Id:        uuid.New().String(),
+		Members:   []Member{{UserID: userID, Role: RoleOwner, AddedAt: now}},
+		CreatedAt: now,
+	}
+	applyProfile(&c, in, sent, true)
+
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading companies")
+		return
+	}
+	if nameTaken(list, c.Name, "") {
+		problem.Write(w, http.StatusConflict, problem.CodeAlreadyExists, "Company with this name already exists")
+		return
+	}
+	if err := Save(append(list, c)); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(c)
+}
+
+// ListHandler возвращает все компании (GET /companies)
+func ListHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading companies")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// MyHandler возвращает компании, в команде которых состоит текущий пользователь (GET /companies/me)
+func MyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading companies")
+		return
+	}
+	mine := []Company{}
+	for _, c := range list {
+		if c.RoleOf(userID) != "" {
+			mine = append(mine, c)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mine)
+}
+
+// OpenHandler возвращает компанию по ID (GET /companies/{id})
+func OpenHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	c, err := Get(r.PathValue("id"))
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading companies")
+		return
+	}
+	if c == nil {
+		problem.Write(w, http.StatusNotFound, problem.CodeCompanyNotFound, "Company not found")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// modify применяет change к компании под блокировкой, если у пользователя есть роль из allowed.
+// change возвращает http.StatusOK или статус, код и текст ошибки.
+func modify(w http.ResponseWriter, r *http.Request, allowed []string, change func(list []Company, c *Company, userID string) (int, string, string)) (Company, bool) {
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return Company{}, false
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading companies")
+		return Company{}, false
+	}
+	for i := range list {
+		if list[i].Id != r.PathValue("id") {
+			continue
+		}
+		permitted := false
+		for _, role := range allowed {
+			if list[i].RoleOf(userID) == role {
+				permitted = true
+			}
+		}
+		if !permitted {
+			problem.Write(w, http.StatusForbidden, problem.CodeForbidden, "Not enough rights in this company")
+			return Company{}, false
+		}
+
+		c := list[i]
+		c.Members = append([]Member(nil), list[i].Members...)
+		if status, code, msg := change(list, &c, userID); status != http.StatusOK {
+			problem.Write(w, status, code, msg)
+			return Company{}, false
+		}
+		list[i] = c
+		if err := Save(list); err != nil {
+			problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
+			return Company{}, false
+		}
+		return c, true
+	}
+	problem.Write(w, http.StatusNotFound, problem.CodeCompanyNotFound, "Company not found")
+	return Company{}, false
+}
+
+// UpdateHandler меняет профиль компании (PUT - все поля, PATCH - только переданные).
+// Доступно владельцам.
+func UpdateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodPatch {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	replace := r.Method == http.MethodPut
+	in, sent, ok := decodeProfile(w, r, replace)
+	if !ok {
+		return
+	}
+
+	c, ok := modify(w, r, []string{RoleOwner}, func(list []Company, c *Company, _ string) (int, string, string) {
+		applyProfile(c, in, sent, replace)
+		if nameTaken(list, c.Name, c.Id) {
+			return http.StatusConflict, problem.CodeAlreadyExists, "Company with this name already exists"
+		}
+		return http.StatusOK, "", ""
+	})
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// AddMemberHandler добавляет пользователя в команду (POST /companies/{id}/members).
+// Поля: user_id или usermail, role (по умолчанию recruiter). Доступно владельцам.
+func AddMemberHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var in MemberInput
+	if _, err := request.Decode(w, r, &in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if err := request.Validate(&in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if in.UserID == "" && in.Usermail == "" {
+		request.WriteError(w, request.Invalid("user_id", "user_id or usermail is required"))
+		return
+	}
+	role := in.Role
+	if role == "" {
+		role = RoleRecruiter
+	}
+
+	users, err := auth.LoadUser()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading users")
+		return
+	}
+	memberID, mail := in.UserID, in.Usermail
+	var user *auth.User
+	for i := range users {
+		if (memberID != "" && users[i].Id == memberID) || (memberID == "" && mail != "" && strings.EqualFold(users[i].Usermail, mail)) {
+			user = &users[i]
+			break
+		}
+	}
+	if user == nil {
+		problem.Write(w, http.StatusNotFound, problem.CodeUserNotFound, "User not found")
+		return
+	}
+	if user.Role != auth.RoleEmployer && user.Role != auth.RoleAdmin {
+		problem.Write(w, http.StatusBadRequest, problem.CodeBadRequest, "Only employer accounts can join a company")
+		return
+	}
+
+	c, ok := modify(w, r, []string{RoleOwner}, func(_ []Company, c *Company, _ string) (int, string, string) {
+		if c.RoleOf(user.Id) != "" {
+			return http.StatusConflict, problem.CodeAlreadyExists, "User is already a member"
+		}
+		c.Members = append(c.Members, Member{UserID: user.Id, Role: role, AddedAt: time.Now().UTC()})
+		return http.StatusOK, "", ""
+	})
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(c)
+}
+
+// MemberHandler меняет роль участника (PUT /companies/{id}/members/{user_id}, поле role)
+// или удаляет его из команды (DELETE). Владельцы управляют всеми, любой участник может выйти сам.
+func MemberHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodDelete {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var in RoleInput
+	if _, err := request.Decode(w, r, &in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if err := request.Validate(&in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if r.Method == http.MethodPut && in.Role == "" {
+		request.WriteError(w, request.Invalid("role", "is required"))
+		return
+	}
+	role := in.Role
+	memberID := r.PathValue("user_id")
+
+	c, ok := modify(w, r, []string{RoleOwner, RoleRecruiter}, func(_ []Company, c *Company, userID string) (int, string, string) {
+		leaving := r.Method == http.MethodDelete && memberID == userID
+		if c.RoleOf(userID) != RoleOwner && !leaving {
+			return http.StatusForbidden, problem.CodeForbidden, "Only owners can manage the team"
+		}
+		current := c.RoleOf(memberID)
+		if current == "" {
+			return http.StatusNotFound, problem.CodeUserNotFound, "Member not found"
+		}
+		// В компании всегда должен оставаться хотя бы один владелец
+		if current == RoleOwner && role != RoleOwner && c.owners() == 1 {
+			return http.StatusConflict, problem.CodeConflict, "Company must keep at least one owner"
+		}
+
+		members := c.Members[:0]
+		for _, m := range c.Members {
+			if m.UserID == memberID {
+				if r.Method == http.MethodDelete {
+					continue
+				}
+				m.Role = role
+			}
+			members = append(members, m)
+		}
+		c.Members = members
+		return http.StatusOK, "", ""
+	})
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// UploadLogoHandler загружает логотип компании (POST /companies/{id}/attachments/logo). Доступно владельцам.
+func UploadLogoHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+	// Проверяем права до приема файла, чтобы не сохранять чужие загрузки
+	c, err := Get(r.PathValue("id"))
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading companies")
+		return
+	}
+	if c == nil {
+		problem.Write(w, http.StatusNotFound, problem.CodeCompanyNotFound, "Company not found")
+		return
+	}
+	if c.RoleOf(userID) != RoleOwner {
+		problem.Write(w, http.StatusForbidden, problem.CodeForbidden, "Not enough rights in this company")
+		return
+	}
+
+	file, err := storage.Receive(w, r, "file", storage.ImageLimits)
+	if err != nil {
+		storage.UploadError(w, err)
+		return
+	}
+
+	var replaced *storage.File
+	_, ok := modify(w, r, []string{RoleOwner}, func(_ []Company, c *Company, _ string) (int, string, string) {
+		replaced, c.Logo = c.Logo, &file
+		return http.StatusOK, "", ""
+	})
+	if !ok {
+		return
+	}
+	// Старый логотип удаляется, если на него больше никто не ссылается
+	if err := storage.Release(replaced.Keys()...); err != nil {
+		fmt.Printf("Ошибка удаления замененного файла: %v\n", err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(file)
+}
+
+// LogoHandler перенаправляет на логотип компании (GET /companies/{id}/attachments/logo, ?thumb=1 - миниатюра)
+func LogoHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	c, err := Get(r.PathValue("id"))
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading companies")
+		return
+	}
+	if c == nil || c.Logo == nil {
+		problem.Write(w, http.StatusNotFound, problem.CodeFileNotFound, "File not found")
+		return
+	}
+	if r.URL.Query().Get("thumb") == "1" {
+		storage.RedirectToFile(w, r, c.Logo.ThumbKey, "thumb.jpg")
+		return
+	}
+	storage.RedirectToFile(w, r, c.Logo.Key, c.Logo.Name)
+}
+
+// VerifyHandler отмечает компанию как проверенную (POST /admin/companies/{id}/verify).
+// DELETE снимает отметку. Доступно только администраторам.
+func VerifyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	adminID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+	if auth.RoleOf(adminID) != auth.RoleAdmin {
+		problem.Write(w, http.StatusForbidden, problem.CodeAdminOnly, "Admin only")
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading companies")
+		return
+	}
+	found := false
+	for i := range list {
+		if list[i].Id == r.PathValue("id") {
+			list[i].Verified = r.Method == http.MethodPost
+			found = true
+			break
+		}
+	}
+	if !found {
+		problem.Write(w, http.StatusNotFound, problem.CodeCompanyNotFound, "Company not found")
+		return
+	}
+	if err := Save(list); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,174 @@
+package dedup
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Режимы проверки на дубликаты
+const (
+	ModeOff   = "off"   // не проверять
+	ModeWarn  = "warn"  // сохранить и вернуть список похожих
+	ModeBlock = "block" // отклонить сохранение
+)
+
+// Config - настройки поиска дубликатов
+type Config struct {
+	Mode string `json:"mode"`
+	// Threshold - минимальная похожесть (0..1), с которой вакансии считаются дубликатами
+	Threshold float64 `json:"threshold"`
+	// AcrossEmployers - сравнивать с вакансиями всех работодателей, а не только
+	// с вакансиями автора и его компании
+	AcrossEmployers bool `json:"across_employers"`
+}
+
+var DefaultConfig = Config{Mode: ModeWarn, Threshold: 0.8}
+
+var configFile string = "duplicates.json"
+
+// LoadConfig читает настройки из duplicates.json, при его отсутствии возвращает DefaultConfig.
+// Незаполненные поля берутся из DefaultConfig.
+func LoadConfig() (Config, error) {
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return DefaultConfig, nil
+		}
+		return Config{}, fmt.Errorf("ошибка чтения файла %s: %w", configFile, err)
+	}
+	cfg := DefaultConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("ошибка разбора JSON из файла %s: %w", configFile, err)
+	}
+	if cfg.Mode != ModeOff && cfg.Mode != ModeWarn && cfg.Mode != ModeBlock {
+		return Config{}, fmt.Errorf("неизвестный режим %q в файле %s", cfg.Mode, configFile)
+	}
+	return cfg, nil
+}
+
+// Doc - документ для сравнения: ID и сигнатура его текста
+type Doc struct {
+	ID        string
+	Signature Signature
+}
+
+func NewDoc(id, text string) Doc {
+	return Doc{ID: id, Signature: Sign(text)}
+}
+
+// Match - похожий документ
+type Match struct {
+	ID         string  `json:"id"`
+	Similarity float64 `json:"similarity"`
+}
+
+// Find возвращает документы корпуса, похожие на doc не меньше чем на threshold,
+// начиная с самых похожих. Сам doc в результат не попадает.
+func Find(doc Doc, corpus []Doc, threshold float64) []Match {
+	matches := []Match{}
+	if doc.Signature == nil {
+		return matches
+	}
+	for _, other := range corpus {
+		if other.ID == doc.ID {
+			continue
+		}
+		if sim := Similarity(doc.Signature, other.Signature); sim >= threshold {
+			matches = append(matches, Match{ID: other.ID, Similarity: sim})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Similarity > matches[j].Similarity })
+	return matches
+}
+
+// Pair - пара похожих документов в кластере
+type Pair struct {
+	A          string  `json:"a"`
+	B          string  `json:"b"`
+	Similarity float64 `json:"similarity"`
+}
+
+// Cluster - группа документов, связанных цепочками похожих пар
+type Cluster struct {
+	IDs   []string `json:"ids"`
+	Pairs []Pair   `json:"pairs"`
+}
+
+// Clusters находит группы дубликатов. Кандидаты в пары берутся из общих корзин LSH,
+// поэтому весь корпус не сравнивается попарно; пары с похожестью от threshold
+// объединяются в кластеры. Кластеры упорядочены по размеру, начиная с крупных.
+func Clusters(docs []Doc, threshold float64) []Cluster {
+	buckets := map[uint64][]int{}
+	for i, d := range docs {
+		if d.Signature == nil {
+			continue
+		}
+		for _, key := range d.Signature.bandKeys() {
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+
+	parent := make([]int, len(docs))
+	for i := range parent {
+		parent[i] = i
+	}
+	var root func(int) int
+	root = func(i int) int {
+		if parent[i] != i {
+			parent[i] = root(parent[i])
+		}
+		return parent[i]
+	}
+
+	seen := map[[2]int]bool{}
+	var pairs []Pair
+	for _, bucket := range buckets {
+		for x := 0; x < len(bucket); x++ {
+			for y := x + 1; y < len(bucket); y++ {
+				i, j := bucket[x], bucket[y]
+				if i == j || seen[[2]int{i, j}] {
+					continue
+				}
+				seen[[2]int{i, j}] = true
+				sim := Similarity(docs[i].Signature, docs[j].Signature)
+				if sim < threshold {
+					continue
+				}
+				pairs = append(pairs, Pair{A: docs[i].ID, B: docs[j].ID, Similarity: sim})
+				parent[root(i)] = root(j)
+			}
+		}
+	}
+
+	byRoot := map[int]*Cluster{}
+	index := map[string]int{}
+	for i, d := range docs {
+		index[d.ID] = i
+	}
+	for _, p := range pairs {
+		r := root(index[p.A])
+		if byRoot[r] == nil {
+			byRoot[r] = &Cluster{}
+		}
+		byRoot[r].Pairs = append(byRoot[r].Pairs, p)
+	}
+	clusters := []Cluster{}
+	for r, c := range byRoot {
+		for i, d := range docs {
+			if root(i) == r {
+				c.IDs = append(c.IDs, d.ID)
+			}
+		}
+		sort.Slice(c.Pairs, func(i, j int) bool { return c.Pairs[i].Similarity > c.Pairs[j].Similarity })
+		clusters = append(clusters, *c)
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if len(clusters[i].IDs) != len(clusters[j].IDs) {
+			return len(clusters[i].IDs) > len(clusters[j].IDs)
+		}
+		return clusters[i].IDs[0] < clusters[j].IDs[0]
+	})
+	return clusters
+}
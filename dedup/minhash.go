@@ -0,0 +1,117 @@
+package dedup
+
+import (
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+// Параметры MinHash: сигнатура из SignatureSize минимумов делится на Bands полос
+// по Rows значений для поиска кандидатов (LSH). При 32 полосах по 4 строки
+// пары с похожестью от 0.8 попадают в общую корзину почти наверняка.
+const (
+	ShingleSize   = 2
+	SignatureSize = 128
+	Bands         = 32
+	Rows          = SignatureSize / Bands
+)
+
+// seeds - по одной соли на хеш-функцию, постоянные между запусками,
+// чтобы сигнатуры можно было сравнивать
+var seeds = func() [SignatureSize]uint64 {
+	var s [SignatureSize]uint64
+	x := uint64(0x9E3779B97F4A7C15)
+	for i := range s {
+		x = mix(x + uint64(i))
+		s[i] = x
+	}
+	return s
+}()
+
+// mix - финализатор splitmix64, равномерно перемешивает биты
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xBF58476D1CE4E5B9
+	x ^= x >> 27
+	x *= 0x94D049BB133111EB
+	x ^= x >> 31
+	return x
+}
+
+// words приводит текст к списку слов: нижний регистр, без пунктуации, ё -> е
+func words(text string) []string {
+	text = strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Shingles возвращает хеши всех последовательностей из ShingleSize слов текста.
+// Короткий текст целиком считается одним шинглом.
+func Shingles(text string) map[uint64]bool {
+	ws := words(text)
+	set := map[uint64]bool{}
+	if len(ws) == 0 {
+		return set
+	}
+	n := ShingleSize
+	if len(ws) < n {
+		n = len(ws)
+	}
+	for i := 0; i+n <= len(ws); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(ws[i:i+n], " ")))
+		set[h.Sum64()] = true
+	}
+	return set
+}
+
+// Signature - MinHash-сигнатура текста; nil для текста без слов
+type Signature []uint64
+
+// Sign вычисляет сигнатуру текста
+func Sign(text string) Signature {
+	shingles := Shingles(text)
+	if len(shingles) == 0 {
+		return nil
+	}
+	sig := make(Signature, SignatureSize)
+	for i := range sig {
+		sig[i] = ^uint64(0)
+	}
+	for sh := range shingles {
+		for i, seed := range seeds {
+			if v := mix(sh ^ seed); v < sig[i] {
+				sig[i] = v
+			}
+		}
+	}
+	return sig
+}
+
+// Similarity оценивает коэффициент Жаккара множеств шинглов по доле совпавших минимумов
+func Similarity(a, b Signature) float64 {
+	if len(a) != SignatureSize || len(b) != SignatureSize {
+		return 0
+	}
+	same := 0
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+	return float64(same) / SignatureSize
+}
+
+// bandKeys возвращает ключи корзин LSH: по одному на полосу сигнатуры
+func (s Signature) bandKeys() []uint64 {
+	keys := make([]uint64, 0, Bands)
+	for b := 0; b < Bands; b++ {
+		h := uint64(b)
+		for _, v := range s[b*Rows : (b+1)*Rows] {
+			h = mix(h ^ v)
+		}
+		keys = append(keys, h)
+	}
+	return keys
+}
@@ -0,0 +1,139 @@
+package dedup
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const vacancy = "Ищем младшего разработчика Go в команду платежей. Задачи: поддержка сервисов " +
+	"на Go, написание тестов, разбор инцидентов вместе с дежурным инженером. Требования: " +
+	"базовое знание Go и SQL, понимание HTTP, готовность учиться. Условия: гибкий график, " +
+	"наставник, оплачиваемое обучение и удаленная работа два дня в неделю."
+
+func TestShingles(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{name: "empty", text: "", want: 0},
+		{name: "only punctuation", text: " ,.!? ", want: 0},
+		{name: "short text is one shingle", text: "Курьер", want: 1},
+		{name: "pairs of words", text: "a b c d", want: 3},
+		{name: "repeated pairs count once", text: "go go go", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(Shingles(tt.text)); got != tt.want {
+				t.Errorf("len(Shingles(%q)) = %d, want %d", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShinglesNormalize(t *testing.T) {
+	a := Shingles("Ёлка, ЗЕЛЁНАЯ!")
+	b := Shingles("елка зеленая")
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("case, punctuation and ё should not change shingles: %v vs %v", a, b)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	edited := strings.Replace(vacancy, "два дня", "три дня", 1)
+	tests := []struct {
+		name     string
+		a, b     Signature
+		min, max float64
+	}{
+		{name: "identical", a: Sign(vacancy), b: Sign(vacancy), min: 1, max: 1},
+		{name: "small edit", a: Sign(vacancy), b: Sign(edited), min: 0.8, max: 1},
+		{name: "unrelated", a: Sign(vacancy), b: Sign("Продавец-консультант в магазин одежды, сменный график"), min: 0, max: 0.1},
+		{name: "empty text", a: Sign(vacancy), b: Sign(""), min: 0, max: 0},
+		{name: "both empty", a: nil, b: nil, min: 0, max: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Similarity(tt.a, tt.b)
+			if got < tt.min || got > tt.max {
+				t.Errorf("Similarity = %.3f, want in [%.2f, %.2f]", got, tt.min, tt.max)
+			}
+			if back := Similarity(tt.b, tt.a); back != got {
+				t.Errorf("Similarity is not symmetric: %.3f vs %.3f", got, back)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	doc := NewDoc("a", vacancy)
+	corpus := []Doc{
+		doc,
+		NewDoc("unrelated", "Продавец-консультант в магазин одежды, сменный график"),
+		NewDoc("close", strings.Replace(vacancy, "два дня", "три дня", 1)),
+		NewDoc("copy", vacancy),
+		NewDoc("empty", ""),
+	}
+	got := Find(doc, corpus, 0.8)
+	var ids []string
+	for _, m := range got {
+		ids = append(ids, m.ID)
+	}
+	if want := []string{"copy", "close"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Find = %v, want %v (most similar first, without the document itself)", ids, want)
+	}
+	if got := Find(NewDoc("empty", ""), corpus, 0); len(got) != 0 {
+		t.Errorf("Find for a document without words = %v, want none", got)
+	}
+}
+
+func TestClusters(t *testing.T) {
+	other := "Требуется бариста в кофейню у метро. Обучение с нуля, гибкий график, " +
+		"питание за счет компании, дружный коллектив и премии по итогам месяца."
+	tests := []struct {
+		name string
+		docs []Doc
+		want [][]string
+	}{
+		{
+			name: "no duplicates",
+			docs: []Doc{NewDoc("a", vacancy), NewDoc("b", other)},
+			want: [][]string{},
+		},
+		{
+			name: "larger cluster first",
+			docs: []Doc{
+				NewDoc("a", vacancy),
+				NewDoc("b", other),
+				NewDoc("c", strings.Replace(vacancy, "два дня", "три дня", 1)),
+				NewDoc("d", other+" Звоните!"),
+				NewDoc("e", vacancy),
+				NewDoc("empty", ""),
+			},
+			want: [][]string{{"a", "c", "e"}, {"b", "d"}},
+		},
+		{
+			name: "empty texts are not duplicates of each other",
+			docs: []Doc{NewDoc("x", ""), NewDoc("y", "")},
+			want: [][]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusters := Clusters(tt.docs, 0.8)
+			got := [][]string{}
+			for _, c := range clusters {
+				got = append(got, c.IDs)
+				for _, p := range c.Pairs {
+					if p.Similarity < 0.8 {
+						t.Errorf("pair %s-%s below threshold: %.3f", p.A, p.B, p.Similarity)
+					}
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Clusters = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
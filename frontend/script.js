@@ -15,15 +15,15 @@ const logoutBtn = document.getElementById('logout-btn');
 
 let isLoggedIn = false;
 let currentUsername = '';
-let currentUserId = null;
 let currentJobId = null;
 
-// НОВАЯ ФУНКЦИЯ: Получение значения куки по имени
-function getCookie(name) {
-    const value = `; ${document.cookie}`;
-    const parts = value.split(`; ${name}=`);
-    if (parts.length === 2) return parts.pop().split(';').shift();
-    return null;
+// Проверяет, может ли текущий пользователь править вакансию: сервер
+// возвращает в /jobs/me вакансии пользователя и его компаний
+async function canManageJob(jobId) {
+    const response = await fetch('/api/v1/jobs/me', { credentials: 'include' });
+    if (!response.ok) return false;
+    const jobs = await response.json();
+    return jobs.some(job => job.id === jobId);
 }
 
 // Показывает нужный контейнер и скрывает остальные
@@ -52,7 +52,6 @@ function updateUI(status) {
         // Не авторизован
         isLoggedIn = false;
         currentUsername = '';
-        currentUserId = null;
         showLoginBtn.style.display = 'inline';
         showSigninBtn.style.display = 'inline';
         welcomeMessage.style.display = 'none';
@@ -70,6 +69,17 @@ function updateUI(status) {
 
 // --- Обработчики форм ---
 
+// Текст ошибки из ответа сервера: ошибки приходят в формате application/problem+json
+async function errorMessage(response) {
+    const contentType = response.headers.get('Content-Type') || '';
+    if (!contentType.includes('application/problem+json')) {
+        return response.text();
+    }
+    const problem = await response.json();
+    const fields = (problem.errors || []).map(e => `${e.field}: ${e.message}`);
+    return [problem.detail || problem.title, ...fields].join('; ');
+}
+
 // Вспомогательная функция для отправки данных формы
 async function submitForm(url, formId, successMessage, afterSuccess) {
     const form = document.getElementById(formId);
@@ -109,7 +119,7 @@ async function submitForm(url, formId, successMessage, afterSuccess) {
                 await afterSuccess(formData);
             }
         } else {
-            const errorText = await response.text();
+            const errorText = await errorMessage(response);
             console.log('Текст ошибки:', errorText);
             messageElement.textContent = `Ошибка (${response.status}): ${errorText}`;
             messageElement.classList.remove('info', 'success');
@@ -124,7 +134,7 @@ async function submitForm(url, formId, successMessage, afterSuccess) {
 // 1. Обработка регистрации
 document.getElementById('signin-form').addEventListener('submit', function(e) {
     e.preventDefault();
-    submitForm('/singin', 'signin-form', 'Регистрация успешна! Теперь Вы можете войти.', 
+    submitForm('/api/v1/users', 'signin-form', 'Регистрация успешна! Теперь Вы можете войти.', 
         async () => {
             document.getElementById('login-form').classList.remove('hidden');
             document.getElementById('signin-form').classList.add('hidden');
@@ -135,7 +145,7 @@ document.getElementById('signin-form').addEventListener('submit', function(e) {
 // 2. Обработка входа
 document.getElementById('login-form').addEventListener('submit', function(e) {
     e.preventDefault();
-    submitForm('/login', 'login-form', 'Вход успешен!', 
+    submitForm('/api/v1/sessions', 'login-form', 'Вход успешен!', 
         async () => {
             await checkAuthStatus();
         }
@@ -145,7 +155,7 @@ document.getElementById('login-form').addEventListener('submit', function(e) {
 // 3. Обработка создания вакансии
 document.getElementById('create-job-form').addEventListener('submit', function(e) {
     e.preventDefault();
-    submitForm('/createjob', 'create-job-form', 'Вакансия успешно создана!', 
+    submitForm('/api/v1/jobs', 'create-job-form', 'Вакансия успешно создана!', 
         async () => {
             await loadJobsList();
             showContainer(jobsListContainer);
@@ -156,7 +166,7 @@ document.getElementById('create-job-form').addEventListener('submit', function(e
 // Проверка статуса авторизации
 async function checkAuthStatus() {
     try {
-        const response = await fetch('/checkauth', {
+        const response = await fetch('/api/v1/sessions/current', {
             method: 'GET',
             credentials: 'include' 
         });
@@ -168,18 +178,9 @@ async function checkAuthStatus() {
             const username = await response.text();
             console.log('Полученное имя пользователя:', username);
             
-            // Получаем ID пользователя из куки
-            const userID = getCookie('id_cookie'); 
-            
-            if (userID) {
-                isLoggedIn = true;
-                currentUsername = username;
-                currentUserId = userID; // Устанавливаем ID
-                updateUI(isLoggedIn);
-            } else {
-                console.warn('Токен авторизации есть, но id_cookie отсутствует.');
-                updateUI(false);
-            }
+            isLoggedIn = true;
+            currentUsername = username;
+            updateUI(isLoggedIn);
             
         } else if (response.status === 401) {
             console.log('Пользователь не авторизован или сессия истекла.');
@@ -201,7 +202,7 @@ async function loadJobsList() {
     messageElement.classList.add('info');
     
     try {
-        const response = await fetch('/showjobs', { 
+        const response = await fetch('/api/v1/jobs', { 
             method: 'GET',
             headers: { 'Accept': 'application/json' },
             credentials: 'include'
@@ -273,7 +274,7 @@ async function loadJobsList() {
             messageElement.textContent = 'Ошибка: Требуется авторизация';
             updateUI(false);
         } else {
-            const errorText = await response.text();
+            const errorText = await errorMessage(response);
             messageElement.textContent = `Ошибка загрузки вакансий: ${errorText}`;
         }
     } catch (error) {
@@ -353,7 +354,7 @@ async function showJobDetails(jobId) {
     
     try {
         // Запрос к исправленному OpenHandler
-        const response = await fetch(`/job/${jobId}`, {
+        const response = await fetch(`/api/v1/jobs/${jobId}`, {
             method: 'GET',
             headers: { 'Accept': 'application/json' },
             credentials: 'include'
@@ -404,9 +405,8 @@ async function showJobDetails(jobId) {
                 </div>
             `;
             
-            // Проверка, что это наша вакансия. Используем job.user_id (JSON-поле из Go)
-            // ИСПРАВЛЕНО: Теперь user_id приходит с бэкенда.
-            if (job.user_id === currentUserId) { 
+            // Кнопки правки - только тем, кому сервер разрешит править вакансию
+            if (await canManageJob(job.id)) { 
                 document.getElementById('edit-job-btn').style.display = 'inline-block';
                 document.getElementById('delete-job-btn').style.display = 'inline-block';
             } else {
@@ -417,7 +417,7 @@ async function showJobDetails(jobId) {
             messageElement.textContent = '';
             showContainer(jobDetailsContainer);
         } else {
-            const errorText = await response.text();
+            const errorText = await errorMessage(response);
             messageElement.textContent = `Ошибка: ${errorText}`;
         }
     } catch (error) {
@@ -437,7 +437,7 @@ async function loadMyJobs() {
     messageElement.classList.add('info');
     
     try {
-        const response = await fetch('/myjobs', {
+        const response = await fetch('/api/v1/jobs/me', {
             method: 'GET',
             headers: { 'Accept': 'application/json' },
             credentials: 'include'
@@ -501,7 +501,7 @@ async function loadMyJobs() {
             messageElement.textContent = 'Требуется авторизация';
             updateUI(false);
         } else {
-            const errorText = await response.text();
+            const errorText = await errorMessage(response);
             messageElement.textContent = `Ошибка: ${errorText}`;
         }
     } catch (error) {
@@ -574,7 +574,7 @@ document.getElementById('delete-job-btn').addEventListener('click', async () =>
     
     try {
         // Запрос к исправленному DeleteHandler
-        const response = await fetch(`/job/${currentJobId}`, {
+        const response = await fetch(`/api/v1/jobs/${currentJobId}`, {
             method: 'DELETE',
             credentials: 'include'
         });
@@ -584,7 +584,7 @@ document.getElementById('delete-job-btn').addEventListener('click', async () =>
             showContainer(jobsListContainer);
             loadJobsList();
         } else {
-            const errorText = await response.text();
+            const errorText = await errorMessage(response);
             alert(`Ошибка удаления: ${errorText}`);
         }
     } catch (error) {
@@ -596,8 +596,8 @@ document.getElementById('delete-job-btn').addEventListener('click', async () =>
 // Выход из системы
 logoutBtn.addEventListener('click', async () => {
     try {
-        const response = await fetch('/logout', {
-            method: 'POST',
+        const response = await fetch('/api/v1/sessions/current', {
+            method: 'DELETE',
             credentials: 'include'
         });
 
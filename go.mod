@@ -3,9 +3,9 @@ module talant
 go 1.24.5
 
 require (
+	github.com/go-pdf/fpdf v0.9.0
 	github.com/golang-jwt/jwt/v5 v5.3.0
 	github.com/google/uuid v1.6.0
+	github.com/gorilla/websocket v1.5.3
 	golang.org/x/crypto v0.45.0
 )
-
-require github.com/gorilla/websocket v1.5.3 // indirect
@@ -0,0 +1,526 @@
+package interview
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"talant/application"
+	"talant/auth"
+	"talant/job"
+	"talant/notification"
+	"talant/problem"
+	"talant/realtime"
+	"talant/request"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// viewLocation выбирает пояс ответа: параметр tz, иначе пояс собеседования
+func viewLocation(r *http.Request, iv Interview) (*time.Location, error) {
+	if tz := r.URL.Query().Get("tz"); tz != "" {
+		return loadLocation(tz)
+	}
+	return loadLocation(iv.TimeZone)
+}
+
+func writeInterview(w http.ResponseWriter, r *http.Request, status int, iv Interview) {
+	loc, err := viewLocation(r, iv)
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, problem.CodeInvalidParameter, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(iv.In(loc))
+}
+
+// announce сообщает другой стороне об изменении собеседования
+func announce(iv Interview, toUserID, title, body string) {
+	realtime.Publish(realtime.TopicApplications, "interview."+iv.Status, toUserID, iv)
+	err := notification.Send(toUserID, notification.EventInterview, title, body, "/interviews/"+iv.Id)
+	if err != nil {
+		fmt.Printf("Ошибка отправки уведомления: %v\n", err)
+	}
+}
+
+// describeSlot - время слота для текста уведомления в поясе собеседования
+func describeSlot(iv Interview, s Slot) string {
+	loc, err := loadLocation(iv.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return s.Start.In(loc).Format("02.01.2006 15:04") + " (" + loc.String() + ")"
+}
+
+// ProposeInput - поля предложения собеседования
+type ProposeInput struct {
+	Slots    []SlotInput `json:"slots"`
+	TimeZone string      `json:"timezone" validate:"trim"`
+	Location string      `json:"location" validate:"trim"`
+	Notes    string      `json:"notes" validate:"trim"`
+}
+
+// AcceptInput - поле выбора слота
+type AcceptInput struct {
+	SlotID string `json:"slot_id" validate:"trim,required"`
+}
+
+// RescheduleInput - поля переноса: работодатель передает slots и при необходимости
+// timezone и location, кандидат - reason
+type RescheduleInput struct {
+	Reason   string      `json:"reason" validate:"trim"`
+	Slots    []SlotInput `json:"slots"`
+	TimeZone string      `json:"timezone" validate:"trim"`
+	Location string      `json:"location" validate:"trim"`
+}
+
+// ReasonInput - поле причины отмены
+type ReasonInput struct {
+	Reason string `json:"reason" validate:"trim"`
+}
+
+// ProposeHandler - работодатель предлагает кандидату слоты собеседования (POST /applications/{id}/interviews).
+// Поля: slots - JSON-массив [{"start", "end"}], timezone - IANA-пояс, location, notes.
+func ProposeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	app, err := application.Get(r.PathValue("id"))
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading applications")
+		return
+	}
+	if app == nil || (!app.ManagedBy(userID) && app.CandidateID != userID) {
+		problem.Write(w, http.StatusNotFound, problem.CodeApplicationNotFound, "Application not found")
+		return
+	}
+	if !app.ManagedBy(userID) {
+		problem.Write(w, http.StatusForbidden, problem.CodeForbidden, "Only the employer can propose an interview")
+		return
+	}
+
+	var in ProposeInput
+	if _, err := request.Decode(w, r, &in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if err := request.Validate(&in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	loc, err := loadLocation(in.TimeZone)
+	if err != nil {
+		request.WriteError(w, request.Invalid("timezone", err.Error()))
+		return
+	}
+	slots, err := parseSlots(in.Slots, loc, time.Now())
+	if err != nil {
+		request.WriteError(w, err)
+		return
+	}
+
+	// Собеседование ведет тот участник команды работодателя, который его назначил
+	now := time.Now().UTC()
+	iv := Interview{
+		Id:            uuid.New().String(),
+		ApplicationID: app.Id,
+		JobID:         app.JobID,
+		EmployerID:    userID,
+		CandidateID:   app.CandidateID,
+		TimeZone:      loc.String(),
+		Slots:         slots,
+		Status:        StatusProposed,
+		Location:      in.Location,
+		Notes:         in.Notes,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	mu.Lock()
+	list, err := Load()
+	if err != nil {
+		mu.Unlock()
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading interviews")
+		return
+	}
+	for _, existing := range list {
+		if existing.ApplicationID == app.Id && existing.Status != StatusCancelled {
+			mu.Unlock()
+			problem.Write(w, http.StatusConflict, problem.CodeAlreadyExists, "Interview already exists for this application, reschedule it instead")
+			return
+		}
+	}
+	err = Save(append(list, iv))
+	mu.Unlock()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
+		return
+	}
+
+	announce(iv, iv.CandidateID, "Приглашение на собеседование",
+		fmt.Sprintf("Работодатель предложил вариантов времени: %d", len(iv.Slots)))
+	writeInterview(w, r, http.StatusCreated, iv)
+}
+
+// ListHandler возвращает собеседования текущего пользователя по возрастанию времени создания (GET /interviews)
+func ListHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading interviews")
+		return
+	}
+
+	var tzLoc *time.Location
+	if tz := r.URL.Query().Get("tz"); tz != "" {
+		if tzLoc, err = loadLocation(tz); err != nil {
+			problem.Write(w, http.StatusBadRequest, problem.CodeInvalidParameter, err.Error())
+			return
+		}
+	}
+
+	mine := []Interview{}
+	for _, iv := range list {
+		if iv.EmployerID != userID && iv.CandidateID != userID {
+			continue
+		}
+		loc := tzLoc
+		if loc == nil {
+			if loc, err = loadLocation(iv.TimeZone); err != nil {
+				loc = time.UTC
+			}
+		}
+		mine = append(mine, iv.In(loc))
+	}
+	sort.SliceStable(mine, func(i, j int) bool { return mine[i].CreatedAt.Before(mine[j].CreatedAt) })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mine)
+}
+
+// find возвращает индекс собеседования, если userID - его участник, иначе -1
+func find(list []Interview, id, userID string) int {
+	for i, iv := range list {
+		if iv.Id == id && (iv.EmployerID == userID || iv.CandidateID == userID) {
+			return i
+		}
+	}
+	return -1
+}
+
+// failure - отказ в изменении собеседования: HTTP-статус, код и текст ответа
+type failure struct {
+	status int
+	code   string
+	detail string
+}
+
+func (f *failure) Error() string { return f.detail }
+
+func fail(status int, code, detail string) error {
+	return &failure{status: status, code: code, detail: detail}
+}
+
+// OpenHandler возвращает собеседование участнику (GET /interviews/{id}, параметр tz)
+func OpenHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading interviews")
+		return
+	}
+	i := find(list, r.PathValue("id"), userID)
+	if i < 0 {
+		problem.Write(w, http.StatusNotFound, problem.CodeInterviewNotFound, "Interview not found")
+		return
+	}
+	writeInterview(w, r, http.StatusOK, list[i])
+}
+
+// modify применяет change к собеседованию под блокировкой и сохраняет результат.
+// change возвращает nil, ошибку проверки запроса (пакет request) или отказ fail.
+func modify(w http.ResponseWriter, r *http.Request, change func(iv *Interview, userID string) error) (Interview, string, bool) {
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return Interview{}, "", false
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading interviews")
+		return Interview{}, "", false
+	}
+	i := find(list, r.PathValue("id"), userID)
+	if i < 0 {
+		problem.Write(w, http.StatusNotFound, problem.CodeInterviewNotFound, "Interview not found")
+		return Interview{}, "", false
+	}
+
+	iv := list[i]
+	if err := change(&iv, userID); err != nil {
+		var f *failure
+		if errors.As(err, &f) {
+			problem.Write(w, f.status, f.code, f.detail)
+		} else {
+			request.WriteError(w, err)
+		}
+		return Interview{}, "", false
+	}
+	iv.Sequence++
+	iv.UpdatedAt = time.Now().UTC()
+	list[i] = iv
+	if err := Save(list); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
+		return Interview{}, "", false
+	}
+	return iv, userID, true
+}
+
+// AcceptHandler - кандидат выбирает один из предложенных слотов (POST /interviews/{id}/accept, поле slot_id)
+func AcceptHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var in AcceptInput
+	if _, err := request.Decode(w, r, &in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if err := request.Validate(&in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	slotID := in.SlotID
+	iv, _, ok := modify(w, r, func(iv *Interview, userID string) error {
+		if userID != iv.CandidateID {
+			return fail(http.StatusForbidden, problem.CodeForbidden, "Only the candidate can accept a slot")
+		}
+		if iv.Status != StatusProposed {
+			return fail(http.StatusConflict, problem.CodeConflict, "Interview is not waiting for a slot choice")
+		}
+		for _, s := range iv.Slots {
+			if s.Id == slotID {
+				if !s.Start.After(time.Now()) {
+					return fail(http.StatusConflict, problem.CodeConflict, "Slot is already in the past")
+				}
+				iv.SlotID = slotID
+				iv.DroppedSlot = nil
+				iv.Status = StatusScheduled
+				iv.RescheduleReason = ""
+				return nil
+			}
+		}
+		return request.Invalid("slot_id", "is not one of the proposed slots")
+	})
+	if !ok {
+		return
+	}
+
+	announce(iv, iv.EmployerID, "Кандидат выбрал время собеседования", describeSlot(iv, *iv.Chosen()))
+	writeInterview(w, r, http.StatusOK, iv)
+}
+
+// RescheduleHandler переносит собеседование (POST /interviews/{id}/reschedule).
+// Работодатель передает новые slots (и при необходимости timezone), кандидат - причину в поле reason.
+func RescheduleHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var in RescheduleInput
+	sent, err := request.Decode(w, r, &in)
+	if err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if err := request.Validate(&in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	reason := in.Reason
+
+	iv, userID, ok := modify(w, r, func(iv *Interview, userID string) error {
+		if iv.Status == StatusCancelled {
+			return fail(http.StatusConflict, problem.CodeConflict, "Interview is cancelled")
+		}
+		if userID == iv.CandidateID {
+			if reason == "" {
+				return request.Invalid("reason", "is required")
+			}
+			iv.Status = StatusRescheduleRequested
+			iv.RescheduleReason = reason
+			return nil
+		}
+
+		tz := iv.TimeZone
+		if sent.Has("timezone") {
+			tz = in.TimeZone
+		}
+		loc, err := loadLocation(tz)
+		if err != nil {
+			return request.Invalid("timezone", err.Error())
+		}
+		slots, err := parseSlots(in.Slots, loc, time.Now())
+		if err != nil {
+			return err
+		}
+		iv.TimeZone = loc.String()
+		if chosen := iv.Chosen(); chosen != nil {
+			iv.DroppedSlot = chosen
+		}
+		iv.Slots = slots
+		iv.SlotID = ""
+		iv.Status = StatusProposed
+		iv.RescheduleReason = reason
+		if sent.Has("location") {
+			iv.Location = in.Location
+		}
+		return nil
+	})
+	if !ok {
+		return
+	}
+
+	if userID == iv.CandidateID {
+		announce(iv, iv.EmployerID, "Кандидат просит перенести собеседование", reason)
+	} else {
+		announce(iv, iv.CandidateID, "Собеседование перенесено",
+			fmt.Sprintf("Работодатель предложил новые варианты времени: %d", len(iv.Slots)))
+	}
+	writeInterview(w, r, http.StatusOK, iv)
+}
+
+// CancelHandler отменяет собеседование любой из сторон (POST /interviews/{id}/cancel, поле reason)
+func CancelHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var in ReasonInput
+	if _, err := request.Decode(w, r, &in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if err := request.Validate(&in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	reason := in.Reason
+	iv, userID, ok := modify(w, r, func(iv *Interview, userID string) error {
+		if iv.Status == StatusCancelled {
+			return fail(http.StatusConflict, problem.CodeConflict, "Interview is already cancelled")
+		}
+		iv.Status = StatusCancelled
+		iv.CancelReason = reason
+		return nil
+	})
+	if !ok {
+		return
+	}
+
+	other := iv.CandidateID
+	if userID == iv.CandidateID {
+		other = iv.EmployerID
+	}
+	announce(iv, other, "Собеседование отменено", reason)
+	writeInterview(w, r, http.StatusOK, iv)
+}
+
+// InviteHandler отдает приглашение iCalendar на выбранный слот (GET /interviews/{id}/invite.ics).
+// После переноса, пока новое время не выбрано, - отмену прежнего события.
+func InviteHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading interviews")
+		return
+	}
+	i := find(list, r.PathValue("id"), userID)
+	if i < 0 {
+		problem.Write(w, http.StatusNotFound, problem.CodeInterviewNotFound, "Interview not found")
+		return
+	}
+	iv := list[i]
+	if iv.Chosen() == nil && iv.DroppedSlot == nil {
+		problem.Write(w, http.StatusConflict, problem.CodeConflict, "Interview time is not chosen yet")
+		return
+	}
+
+	title := ""
+	jobs, err := job.LoadJobs()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading jobs")
+		return
+	}
+	for _, j := range jobs {
+		if j.Id == iv.JobID {
+			title = j.Title
+			break
+		}
+	}
+
+	data, err := Calendar(iv, title, party(iv.EmployerID), party(iv.CandidateID), time.Now())
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error rendering invite")
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="interview.ics"`)
+	w.Write(data)
+}
+
+func party(userID string) Party {
+	user, err := auth.GetUser(userID)
+	if err != nil || user == nil {
+		return Party{}
+	}
+	return Party{Name: user.Username, Email: user.Usermail}
+}
@@ -0,0 +1,112 @@
+package interview
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// icsTime - формат даты-времени iCalendar в UTC. Время в UTC однозначно
+// в любом календаре и не требует описывать пояс блоком VTIMEZONE.
+const icsTime = "20060102T150405Z"
+
+// Party - участник собеседования в приглашении
+type Party struct {
+	Name  string
+	Email string
+}
+
+// Calendar строит приглашение iCalendar (RFC 5545) для выбранного слота.
+// Отмененное собеседование выдается с METHOD:CANCEL, чтобы календарь удалил событие;
+// так же отменяется слот, снятый переносом, пока новое время не выбрано. Пока
+// кандидат просит перенос, событие остается предварительным (TENTATIVE).
+// UID у всех версий один, SEQUENCE растет, поэтому календарь заменяет событие.
+func Calendar(iv Interview, title string, organizer, attendee Party, now time.Time) ([]byte, error) {
+	slot := iv.Chosen()
+	method, status := "REQUEST", "CONFIRMED"
+	switch {
+	case slot == nil && iv.DroppedSlot != nil:
+		slot, method, status = iv.DroppedSlot, "CANCEL", "CANCELLED"
+	case slot == nil:
+		return nil, fmt.Errorf("interview has no chosen slot")
+	case iv.Status == StatusCancelled:
+		method, status = "CANCEL", "CANCELLED"
+	case iv.Status == StatusRescheduleRequested:
+		status = "TENTATIVE"
+	}
+
+	var b bytes.Buffer
+	line := func(s string) {
+		b.WriteString(fold(s))
+		b.WriteString("\r\n")
+	}
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Talant//Interviews//RU")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:" + method)
+	line("BEGIN:VEVENT")
+	line("UID:" + iv.Id + "@talant")
+	line(fmt.Sprintf("SEQUENCE:%d", iv.Sequence))
+	line("DTSTAMP:" + now.UTC().Format(icsTime))
+	line("DTSTART:" + slot.Start.UTC().Format(icsTime))
+	line("DTEND:" + slot.End.UTC().Format(icsTime))
+	line("SUMMARY:" + escapeText("Собеседование: "+title))
+	if iv.Location != "" {
+		line("LOCATION:" + escapeText(iv.Location))
+	}
+	if iv.Notes != "" {
+		line("DESCRIPTION:" + escapeText(iv.Notes))
+	}
+	if organizer.Email != "" {
+		line("ORGANIZER;CN=" + paramValue(organizer.Name) + ":mailto:" + organizer.Email)
+	}
+	if attendee.Email != "" {
+		line("ATTENDEE;CN=" + paramValue(attendee.Name) + ";ROLE=REQ-PARTICIPANT;PARTSTAT=ACCEPTED:mailto:" + attendee.Email)
+	}
+	line("STATUS:" + status)
+	line("END:VEVENT")
+	line("END:VCALENDAR")
+	return b.Bytes(), nil
+}
+
+// escapeText экранирует значение типа TEXT (RFC 5545, 3.3.11)
+func escapeText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+	return r.Replace(s)
+}
+
+// paramValue заключает значение параметра в кавычки, если в нем есть спецсимволы
+func paramValue(s string) string {
+	s = strings.ReplaceAll(s, `"`, "")
+	if strings.ContainsAny(s, ":;,") {
+		return `"` + s + `"`
+	}
+	return s
+}
+
+// fold разбивает строку длиннее 75 октетов на строки продолжения (RFC 5545, 3.1),
+// не разрывая многобайтовые символы UTF-8
+func fold(s string) string {
+	const limit = 75
+	if len(s) <= limit {
+		return s
+	}
+	var b strings.Builder
+	width := limit
+	for len(s) > width {
+		cut := width
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		// Пробел в начале строки продолжения занимает один октет
+		width = limit - 1
+	}
+	b.WriteString(s)
+	return b.String()
+}
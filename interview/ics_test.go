@@ -0,0 +1,156 @@
+package interview
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{in: "Офис на Тверской", want: "Офис на Тверской"},
+		{in: `C:\path`, want: `C:\\path`},
+		{in: "Москва, ул. Ленина; 3 этаж", want: `Москва\, ул. Ленина\; 3 этаж`},
+		{in: "строка 1\nстрока 2", want: `строка 1\nстрока 2`},
+		{in: "строка 1\r\nстрока 2\rстрока 3", want: `строка 1\nстрока 2\nстрока 3`},
+		// Обратная косая черта экранируется первой, иначе экранирование удвоится
+		{in: `\,`, want: `\\\,`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := escapeText(tt.in); got != tt.want {
+				t.Errorf("escapeText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParamValue(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{in: "Анна Петрова", want: "Анна Петрова"},
+		{in: "Петрова, Анна", want: `"Петрова, Анна"`},
+		{in: "ООО Ромашка: HR", want: `"ООО Ромашка: HR"`},
+		{in: "a;b", want: `"a;b"`},
+		// Кавычки в значении параметра запрещены, их негде экранировать
+		{in: `ООО "Ромашка"`, want: "ООО Ромашка"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := paramValue(tt.in); got != tt.want {
+				t.Errorf("paramValue(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		lines int
+	}{
+		{name: "empty", in: "", lines: 1},
+		{name: "exactly 75 octets", in: strings.Repeat("a", 75), lines: 1},
+		{name: "76 octets", in: strings.Repeat("a", 76), lines: 2},
+		{name: "continuation counts the space", in: strings.Repeat("a", 75+74), lines: 2},
+		{name: "continuation overflow", in: strings.Repeat("a", 75+75), lines: 3},
+		{name: "cyrillic", in: "SUMMARY:" + strings.Repeat("ж", 100), lines: 3},
+		{name: "four-byte runes", in: strings.Repeat("😀", 40), lines: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fold(tt.in)
+			lines := strings.Split(got, "\r\n")
+			if len(lines) != tt.lines {
+				t.Errorf("fold gave %d lines, want %d: %q", len(lines), tt.lines, got)
+			}
+			var unfolded strings.Builder
+			for i, l := range lines {
+				if len(l) > 75 {
+					t.Errorf("line %d is %d octets long", i, len(l))
+				}
+				if !utf8.ValidString(l) {
+					t.Errorf("line %d splits a UTF-8 character: %q", i, l)
+				}
+				if i > 0 {
+					if !strings.HasPrefix(l, " ") {
+						t.Fatalf("continuation line %d does not start with a space: %q", i, l)
+					}
+					l = l[1:]
+				}
+				unfolded.WriteString(l)
+			}
+			if unfolded.String() != tt.in {
+				t.Errorf("unfolded = %q, want %q", unfolded.String(), tt.in)
+			}
+		})
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	start := time.Date(2026, 3, 20, 10, 0, 0, 0, time.UTC)
+	slot := Slot{Id: "s1", Start: start, End: start.Add(time.Hour)}
+	moved := Slot{Id: "s2", Start: start.Add(24 * time.Hour), End: start.Add(25 * time.Hour)}
+	tests := []struct {
+		name    string
+		iv      Interview
+		method  string
+		status  string
+		dtstart string
+		wantErr bool
+	}{
+		{name: "scheduled",
+			iv:     Interview{Slots: []Slot{slot}, SlotID: "s1", Status: StatusScheduled, Sequence: 1},
+			method: "REQUEST", status: "CONFIRMED", dtstart: "20260320T100000Z"},
+		{name: "reschedule requested",
+			iv:     Interview{Slots: []Slot{slot}, SlotID: "s1", Status: StatusRescheduleRequested, Sequence: 2},
+			method: "REQUEST", status: "TENTATIVE", dtstart: "20260320T100000Z"},
+		{name: "cancelled",
+			iv:     Interview{Slots: []Slot{slot}, SlotID: "s1", Status: StatusCancelled, Sequence: 2},
+			method: "CANCEL", status: "CANCELLED", dtstart: "20260320T100000Z"},
+		{name: "slot dropped by reschedule",
+			iv:     Interview{Slots: []Slot{moved}, DroppedSlot: &slot, Status: StatusProposed, Sequence: 3},
+			method: "CANCEL", status: "CANCELLED", dtstart: "20260320T100000Z"},
+		{name: "rescheduled slot accepted",
+			iv:     Interview{Slots: []Slot{moved}, SlotID: "s2", Status: StatusScheduled, Sequence: 4},
+			method: "REQUEST", status: "CONFIRMED", dtstart: "20260321T100000Z"},
+		{name: "no slot chosen",
+			iv:      Interview{Slots: []Slot{slot}, Status: StatusProposed},
+			wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.iv.Id = "iv1"
+			data, err := Calendar(tt.iv, "Go-разработчик", Party{Name: "ООО Ромашка", Email: "hr@example.com"},
+				Party{Name: "Анна", Email: "anna@example.com"}, start.Add(-time.Hour))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Calendar succeeded without a chosen slot")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			ics := string(data)
+			for _, want := range []string{
+				"METHOD:" + tt.method,
+				"STATUS:" + tt.status,
+				"DTSTART:" + tt.dtstart,
+				// UID одинаков во всех версиях, чтобы календарь заменял событие
+				"UID:iv1@talant",
+				fmt.Sprintf("SEQUENCE:%d", tt.iv.Sequence),
+			} {
+				if !strings.Contains(ics, "\r\n"+want+"\r\n") {
+					t.Errorf("calendar has no line %q:\n%s", want, ics)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package interview
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"talant/request"
+	"time"
+	// База часовых поясов встроена в бинарник, чтобы не зависеть от tzdata на сервере
+	_ "time/tzdata"
+
+	"github.com/google/uuid"
+)
+
+// Статусы собеседования
+const (
+	StatusProposed            = "proposed"             // работодатель предложил слоты, кандидат выбирает
+	StatusScheduled           = "scheduled"            // кандидат выбрал слот
+	StatusRescheduleRequested = "reschedule_requested" // кандидат попросил другое время
+	StatusCancelled           = "cancelled"
+)
+
+// DefaultTimeZone - часовой пояс, если работодатель его не указал
+const DefaultTimeZone = "Europe/Moscow"
+
+// MaxSlots - сколько слотов можно предложить за раз
+const MaxSlots = 10
+
+// Slot - предложенный интервал времени. Хранится в UTC,
+// в ответах переводится в часовой пояс собеседования или зрителя.
+type Slot struct {
+	Id    string    `json:"id"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Interview - собеседование по отклику
+type Interview struct {
+	Id            string `json:"id"`
+	ApplicationID string `json:"application_id"`
+	JobID         string `json:"job_id"`
+	EmployerID    string `json:"employer_id"`
+	CandidateID   string `json:"candidate_id"`
+	// TimeZone - IANA-имя пояса, в котором работодатель задавал слоты
+	TimeZone string `json:"time_zone"`
+	Slots    []Slot `json:"slots"`
+	// SlotID - слот, выбранный кандидатом
+	SlotID string `json:"slot_id,omitempty"`
+	// DroppedSlot - ранее выбранный слот, снятый переносом: приглашение на него
+	// отменяется (METHOD:CANCEL), пока кандидат не выберет новое время
+	DroppedSlot      *Slot  `json:"dropped_slot,omitempty"`
+	Status           string `json:"status"`
+	Location         string `json:"location,omitempty"`
+	Notes            string `json:"notes,omitempty"`
+	RescheduleReason string `json:"reschedule_reason,omitempty"`
+	CancelReason     string `json:"cancel_reason,omitempty"`
+	// Sequence - номер ревизии приглашения (SEQUENCE в iCalendar), растет при каждом изменении
+	Sequence  int       `json:"sequence"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+var interviewsFile string = "interviews.json"
+
+// mu сериализует изменения interviews.json
+var mu sync.Mutex
+
+func Load() ([]Interview, error) {
+	data, err := os.ReadFile(interviewsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Interview{}, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла %s: %w", interviewsFile, err)
+	}
+	if len(data) == 0 {
+		return []Interview{}, nil
+	}
+
+	var list []Interview
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("ошибка разбора JSON из файла %s: %w", interviewsFile, err)
+	}
+	return list, nil
+}
+
+func Save(list []Interview) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования в JSON: %w", err)
+	}
+	if err := os.WriteFile(interviewsFile, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", interviewsFile, err)
+	}
+	return nil
+}
+
+// Chosen возвращает выбранный кандидатом слот или nil
+func (iv Interview) Chosen() *Slot {
+	for i := range iv.Slots {
+		if iv.Slots[i].Id == iv.SlotID {
+			return &iv.Slots[i]
+		}
+	}
+	return nil
+}
+
+// In возвращает копию собеседования со временем слотов в поясе loc
+func (iv Interview) In(loc *time.Location) Interview {
+	slots := make([]Slot, len(iv.Slots))
+	for i, s := range iv.Slots {
+		slots[i] = Slot{Id: s.Id, Start: s.Start.In(loc), End: s.End.In(loc)}
+	}
+	iv.Slots = slots
+	return iv
+}
+
+// loadLocation разбирает IANA-имя пояса, пустое имя означает DefaultTimeZone
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		name = DefaultTimeZone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return loc, nil
+}
+
+// localLayout - время без смещения, оно понимается в поясе собеседования
+const localLayout = "2006-01-02T15:04"
+
+// parseTime принимает RFC 3339 со смещением или местное время в поясе loc.
+// Местное время, которого нет из-за перевода часов, отклоняется.
+func parseTime(raw string, loc *time.Location) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	for _, layout := range []string{time.RFC3339, localLayout + "Z07:00"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	t, err := time.ParseInLocation(localLayout, raw, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q: expected RFC 3339 or %s", raw, localLayout)
+	}
+	if t.Format(localLayout) != raw {
+		return time.Time{}, fmt.Errorf("%q does not exist in %s", raw, loc)
+	}
+	return t.UTC(), nil
+}
+
+// SlotInput - интервал в запросе: RFC 3339 со смещением или местное время в поясе собеседования
+type SlotInput struct {
+	Start string `json:"start" validate:"required"`
+	End   string `json:"end" validate:"required"`
+}
+
+// parseSlots переводит интервалы запроса в слоты и проверяет их
+func parseSlots(in []SlotInput, loc *time.Location, now time.Time) ([]Slot, error) {
+	if len(in) == 0 || len(in) > MaxSlots {
+		return nil, request.Invalid("slots", fmt.Sprintf("must have from 1 to %d items", MaxSlots))
+	}
+
+	slots := make([]Slot, 0, len(in))
+	for i, s := range in {
+		field := fmt.Sprintf("slots[%d]", i)
+		start, err := parseTime(s.Start, loc)
+		if err != nil {
+			return nil, request.Invalid(field+".start", err.Error())
+		}
+		end, err := parseTime(s.End, loc)
+		if err != nil {
+			return nil, request.Invalid(field+".end", err.Error())
+		}
+		if !end.After(start) {
+			return nil, request.Invalid(field+".end", "must be after start")
+		}
+		if end.Sub(start) > 8*time.Hour {
+			return nil, request.Invalid(field, "must not be longer than 8 hours")
+		}
+		if !start.After(now) {
+			return nil, request.Invalid(field+".start", "must be in the future")
+		}
+		slots = append(slots, Slot{Id: uuid.New().String(), Start: start, End: end})
+	}
+	return slots, nil
+}
@@ -0,0 +1,114 @@
+package job
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"talant/ankety"
+	"talant/application"
+	"talant/auth"
+	"talant/notification"
+	"talant/problem"
+	"talant/realtime"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ApplyHandler создает отклик текущего пользователя на вакансию (/job/{id}/apply).
+// К отклику прикладывается анкета кандидата, без анкеты откликнуться нельзя.
+func ApplyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	jobs, err := LoadJobs()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading jobs: "+err.Error())
+		return
+	}
+	var found *Job
+	for i := range jobs {
+		if jobs[i].Id == r.PathValue("id") {
+			found = &jobs[i]
+			break
+		}
+	}
+	if found == nil || !found.Moderation.Visible() {
+		problem.Write(w, http.StatusNotFound, problem.CodeJobNotFound, "Job not found")
+		return
+	}
+	if found.ManagedBy(userID) {
+		problem.Write(w, http.StatusBadRequest, problem.CodeOwnJob, "Cannot apply to your own job")
+		return
+	}
+
+	anketa, err := ankety.FindByUser(userID)
+	if errors.Is(err, ankety.ErrDuplicateAnkety) {
+		problem.Write(w, http.StatusConflict, problem.CodeConflict, err.Error())
+		return
+	}
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading ankety")
+		return
+	}
+	if anketa == nil {
+		problem.Write(w, http.StatusBadRequest, problem.CodeAnketyRequired, "Create an ankety before applying")
+		return
+	}
+	if anketa.Minor {
+		if !found.AcceptsMinors {
+			problem.Write(w, http.StatusForbidden, problem.CodeAdultsOnly, "This job is for adults only")
+			return
+		}
+		if anketa.Guardian == nil {
+			problem.Write(w, http.StatusForbidden, problem.CodeGuardianConsentRequired, "Guardian consent is required for minors")
+			return
+		}
+	}
+
+	jobRevision, err := currentRevision(*found)
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading revisions")
+		return
+	}
+
+	app := application.Application{
+		Id:          uuid.New().String(),
+		JobID:       found.Id,
+		CandidateID: userID,
+		EmployerID:  found.UserID,
+		CompanyID:   found.CompanyID,
+		AnketyID:    anketa.Id,
+		Status:      application.StatusApplied,
+		CreatedAt:   time.Now().UTC(),
+		JobRevision: jobRevision,
+	}
+	if err := application.Add(app); err != nil {
+		if errors.Is(err, application.ErrAlreadyApplied) {
+			problem.Write(w, http.StatusConflict, problem.CodeAlreadyApplied, "Already applied to this job")
+			return
+		}
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving application")
+		return
+	}
+
+	realtime.Publish(realtime.TopicApplications, "application.created", found.UserID, app)
+	err = notification.Send(found.UserID, notification.EventNewApplicant,
+		"Новый отклик на вакансию «"+found.Title+"»", anketa.Name, "/job/"+found.Id+"/applications")
+	if err != nil {
+		fmt.Printf("Ошибка отправки уведомления: %v\n", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(app)
+}
@@ -0,0 +1,132 @@
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"talant/auth"
+	"talant/dedup"
+	"talant/problem"
+	"time"
+)
+
+// duplicateText - текст вакансии, по которому ищутся дубликаты
+func duplicateText(j Job) string {
+	return j.Title + "\n" + j.Description
+}
+
+// duplicatesOf ищет среди действующих вакансий похожие на j. Без AcrossEmployers
+// сравнение идет только с вакансиями того же автора или той же компании.
+func duplicatesOf(j Job, jobs []Job, cfg dedup.Config, now time.Time) []dedup.Match {
+	var corpus []dedup.Doc
+	for _, other := range jobs {
+		if other.Id == j.Id || other.Expired(now) {
+			continue
+		}
+		sameOwner := other.UserID == j.UserID || (j.CompanyID != "" && other.CompanyID == j.CompanyID)
+		if !cfg.AcrossEmployers && !sameOwner {
+			continue
+		}
+		corpus = append(corpus, dedup.NewDoc(other.Id, duplicateText(other)))
+	}
+	return dedup.Find(dedup.NewDoc(j.Id, duplicateText(j)), corpus, cfg.Threshold)
+}
+
+// checkDuplicates проверяет вакансию перед сохранением. В режиме block при найденных
+// дубликатах отвечает 409 и возвращает false, в режиме warn возвращает похожие вакансии.
+func checkDuplicates(w http.ResponseWriter, j Job, jobs []Job) ([]dedup.Match, bool) {
+	cfg, err := dedup.LoadConfig()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading duplicates config")
+		return nil, false
+	}
+	if cfg.Mode == dedup.ModeOff {
+		return nil, true
+	}
+	matches := duplicatesOf(j, jobs, cfg, time.Now())
+	if cfg.Mode == dedup.ModeBlock && len(matches) > 0 {
+		problem.Write(w, http.StatusConflict, problem.CodeDuplicateJob, fmt.Sprintf("Duplicate job: %.0f%% similar to job %s", matches[0].Similarity*100, matches[0].ID))
+		return nil, false
+	}
+	return matches, true
+}
+
+// DuplicateJob - вакансия в отчете о дубликатах
+type DuplicateJob struct {
+	Id        string    `json:"id"`
+	Title     string    `json:"title"`
+	UserID    string    `json:"user_id"`
+	CompanyID string    `json:"company_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DuplicateCluster - группа похожих вакансий
+type DuplicateCluster struct {
+	Jobs  []DuplicateJob `json:"jobs"`
+	Pairs []dedup.Pair   `json:"pairs"`
+}
+
+// DuplicatesHandler возвращает администратору кластеры похожих вакансий всех работодателей
+// (GET /admin/duplicates). Параметры: threshold (по умолчанию из настроек),
+// all=true - учитывать и истекшие вакансии.
+func DuplicatesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+	if auth.RoleOf(userID) != auth.RoleAdmin {
+		problem.Write(w, http.StatusForbidden, problem.CodeAdminOnly, "Admin only")
+		return
+	}
+
+	cfg, err := dedup.LoadConfig()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading duplicates config")
+		return
+	}
+	threshold := cfg.Threshold
+	if raw := r.URL.Query().Get("threshold"); raw != "" {
+		threshold, err = strconv.ParseFloat(raw, 64)
+		if err != nil || threshold <= 0 || threshold > 1 {
+			problem.Write(w, http.StatusBadRequest, problem.CodeInvalidParameter, "Threshold must be a number in (0, 1]")
+			return
+		}
+	}
+
+	jobs, err := LoadJobs()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading jobs: "+err.Error())
+		return
+	}
+	now := time.Now()
+	all := r.URL.Query().Get("all") == "true"
+	byID := map[string]Job{}
+	var docs []dedup.Doc
+	for _, j := range jobs {
+		if !all && j.Expired(now) {
+			continue
+		}
+		byID[j.Id] = j
+		docs = append(docs, dedup.NewDoc(j.Id, duplicateText(j)))
+	}
+
+	result := []DuplicateCluster{}
+	for _, c := range dedup.Clusters(docs, threshold) {
+		cluster := DuplicateCluster{Pairs: c.Pairs}
+		for _, id := range c.IDs {
+			j := byID[id]
+			cluster.Jobs = append(cluster.Jobs, DuplicateJob{Id: j.Id, Title: j.Title, UserID: j.UserID, CompanyID: j.CompanyID, CreatedAt: j.CreatedAt})
+		}
+		result = append(result, cluster)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
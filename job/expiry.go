@@ -0,0 +1,78 @@
+package job
+
+import (
+	"fmt"
+	"talant/notification"
+	"time"
+)
+
+// ExpiryWarning - за сколько до окончания публикации автор получает уведомление
+const ExpiryWarning = 3 * 24 * time.Hour
+
+const expiresLayout = "2006-01-02"
+
+// parseExpiresAt разбирает дату окончания публикации, формат которой проверило
+// правило date у Input.ExpiresAt; пустая строка - без срока.
+// Вакансия снимается в конце указанного дня.
+func parseExpiresAt(s string) *time.Time {
+	day, err := time.Parse(expiresLayout, s)
+	if err != nil {
+		return nil
+	}
+	end := day.Add(24*time.Hour - time.Second)
+	return &end
+}
+
+// Expired сообщает, закончился ли срок публикации вакансии
+func (j Job) Expired(now time.Time) bool {
+	return j.ExpiresAt != nil && now.After(*j.ExpiresAt)
+}
+
+// NotifyExpiring предупреждает авторов вакансий, срок которых скоро закончится
+func NotifyExpiring(now time.Time) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	jobs, err := LoadJobs()
+	if err != nil {
+		return err
+	}
+	changed := false
+	for i, j := range jobs {
+		if j.ExpiresAt == nil || j.ExpiryNotified || j.Expired(now) || j.ExpiresAt.Sub(now) > ExpiryWarning {
+			continue
+		}
+		err := notification.Send(j.UserID, notification.EventJobExpiring,
+			"Вакансия «"+j.Title+"» скоро будет снята",
+			"Публикация заканчивается "+j.ExpiresAt.Format("02.01.2006"), "/job/"+j.Id)
+		if err != nil {
+			return err
+		}
+		jobs[i].ExpiryNotified = true
+		changed = true
+	}
+	if changed {
+		return SaveJobs(jobs)
+	}
+	return nil
+}
+
+// StartExpiryWatcher раз в час проверяет сроки публикации вакансий
+func StartExpiryWatcher() {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			if err := NotifyExpiring(now); err != nil {
+				fmt.Printf("Ошибка проверки сроков вакансий: %v\n", err)
+			}
+		}
+	}()
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
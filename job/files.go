@@ -0,0 +1,133 @@
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"talant/auth"
+	"talant/problem"
+	"talant/storage"
+)
+
+// UploadLogoHandler загружает логотип компании к вакансии (/job/{id}/attachments/logo)
+func UploadLogoHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+	jobID := r.PathValue("id")
+
+	jobs, err := LoadJobs()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading jobs: "+err.Error())
+		return
+	}
+	if status, code, msg := checkOwner(jobs, jobID, userID); status != http.StatusOK {
+		problem.Write(w, status, code, msg)
+		return
+	}
+
+	file, err := storage.Receive(w, r, "file", storage.ImageLimits)
+	if err != nil {
+		storage.UploadError(w, err)
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	jobs, err = LoadJobs()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading jobs: "+err.Error())
+		return
+	}
+	if status, code, msg := checkOwner(jobs, jobID, userID); status != http.StatusOK {
+		problem.Write(w, status, code, msg)
+		return
+	}
+	var replaced *storage.File
+	for i := range jobs {
+		if jobs[i].Id == jobID {
+			replaced = jobs[i].Logo
+			jobs[i].Logo = &file
+			jobs[i].Version = jobs[i].nextVersion()
+			break
+		}
+	}
+	if err := SaveJobs(jobs); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving jobs: "+err.Error())
+		return
+	}
+	// Старый логотип удаляется, если на него больше никто не ссылается
+	if err := storage.Release(replaced.Keys()...); err != nil {
+		fmt.Printf("Ошибка удаления замененного файла: %v\n", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(file)
+}
+
+// checkOwner возвращает http.StatusOK, если вакансия существует и userID может ею управлять
+func checkOwner(jobs []Job, jobID, userID string) (int, string, string) {
+	for _, job := range jobs {
+		if job.Id == jobID {
+			if !job.ManagedBy(userID) {
+				return http.StatusForbidden, problem.CodeForbidden, "Cannot edit other user's job"
+			}
+			return http.StatusOK, "", ""
+		}
+	}
+	return http.StatusNotFound, problem.CodeJobNotFound, "Job not found"
+}
+
+// LogoHandler перенаправляет на временную ссылку на логотип (/job/{id}/attachments/logo,
+// ?thumb=1 - миниатюра). Логотипы публичны, авторизация не нужна.
+func LogoHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	jobs, err := LoadJobs()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading jobs: "+err.Error())
+		return
+	}
+
+	jobID := r.PathValue("id")
+	for _, job := range jobs {
+		if job.Id != jobID {
+			continue
+		}
+		if job.Logo == nil {
+			problem.Write(w, http.StatusNotFound, problem.CodeFileNotFound, "File not found")
+			return
+		}
+		if r.URL.Query().Get("thumb") != "" {
+			storage.RedirectToFile(w, r, job.Logo.ThumbKey, "logo-thumb.jpg")
+			return
+		}
+		storage.RedirectToFile(w, r, job.Logo.Key, job.Logo.Name)
+		return
+	}
+	problem.Write(w, http.StatusNotFound, problem.CodeJobNotFound, "Job not found")
+}
+
+// FileKeys возвращает ключи логотипов всех вакансий, см. storage.OnReferences
+func FileKeys() ([]string, error) {
+	jobs, err := LoadJobs()
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, j := range jobs {
+		keys = append(keys, j.Logo.Keys()...)
+	}
+	return keys, nil
+}
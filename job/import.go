@@ -0,0 +1,154 @@
+package job
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"talant/auth"
+	"talant/moderation"
+	"talant/problem"
+	"talant/quota"
+	"talant/request"
+	"talant/school"
+	"time"
+)
+
+// ImportInput - тело запроса массового импорта вакансий, до 100 за раз
+type ImportInput struct {
+	Jobs []Input `json:"jobs" validate:"required,min=1,max=100"`
+}
+
+// ImportResult - созданные вакансии в порядке запроса
+type ImportResult struct {
+	Jobs []Job `json:"jobs"`
+}
+
+// ImportHandler создает несколько вакансий одним запросом (POST /api/v1/jobs/import).
+// Каждая вакансия проходит те же проверки, что и в CreateHandler. Импорт атомарный:
+// если хотя бы одна не прошла, не создается ни одна, а в ответе - все нарушения
+// сразу, с именами полей вида "jobs[2].title".
+func ImportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	var in ImportInput
+	if _, err := request.Decode(w, r, &in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+
+	// Validate проверяет сам список и формат expires_at каждого элемента,
+	// validateJob - вакансии, собранные из элементов
+	errs := []error{request.Validate(&in)}
+	imported := make([]Job, len(in.Jobs))
+	for i, item := range in.Jobs {
+		prefix := fmt.Sprintf("jobs[%d]", i)
+		j := fromInput(item, userID)
+		errs = append(errs, request.Within(prefix, validateJob(&j)))
+		if j.CompanyID != "" {
+			c, status, msg := resolveCompany(j.CompanyID, userID)
+			switch status {
+			case http.StatusOK:
+				j.Company = c.Name
+			case http.StatusInternalServerError:
+				problem.Write(w, status, problem.CodeInternal, msg)
+				return
+			default:
+				errs = append(errs, request.Invalid(prefix+".company_id", msg))
+			}
+		}
+		j.SchoolID, j.School, err = school.Resolve(item.SchoolID, j.School)
+		if errors.Is(err, school.ErrUnknownSchool) {
+			errs = append(errs, request.Invalid(prefix+".school_id", err.Error()))
+		} else if err != nil {
+			problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading schools: "+err.Error())
+			return
+		}
+		imported[i] = j
+	}
+	if err := request.Join(errs...); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+
+	// Как в CreateHandler: квота, дубликаты и сохранение - под одной блокировкой.
+	// Вакансии импорта учитываются в квоте и сверяются на дубликаты друг с другом.
+	mu.Lock()
+	defer mu.Unlock()
+	jobs, err := LoadJobs()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading jobs: "+err.Error())
+		return
+	}
+	var reservations []*quota.Reservation
+	submitted := 0
+	cancel := func() {
+		for _, res := range reservations {
+			res.Cancel()
+		}
+		// Вакансии не созданы - их не должно быть и в очереди модерации
+		for _, j := range imported[:submitted] {
+			if err := moderation.Withdraw(moderation.KindJob, j.Id); err != nil {
+				fmt.Printf("Ошибка очистки очереди модерации: %v\n", err)
+			}
+		}
+	}
+	now := time.Now()
+	for i := range imported {
+		dups, ok := checkDuplicates(w, imported[i], jobs)
+		if !ok {
+			cancel()
+			return
+		}
+		imported[i].Duplicates = dups
+		reservation, err := quota.Reserve(userID, usageOf(jobs, userID, now), quota.Request{
+			Create:   true,
+			Activate: !imported[i].Expired(now),
+			Feature:  imported[i].Featured,
+		})
+		if err != nil {
+			cancel()
+			quota.Error(w, err)
+			return
+		}
+		reservations = append(reservations, reservation)
+		jobs = append(jobs, imported[i])
+	}
+	// Модерация - после всех проверок; если дальше что-то не удастся, cancel уберет
+	// поставленные в очередь вакансии
+	for i := range imported {
+		imported[i].Moderation, err = moderation.Submit(moderationContent(imported[i]))
+		if err != nil {
+			cancel()
+			problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Moderation error: "+err.Error())
+			return
+		}
+		submitted = i + 1
+	}
+	copy(jobs[len(jobs)-len(imported):], imported)
+
+	if err := SaveJobs(jobs); err != nil {
+		cancel()
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving jobs: "+err.Error())
+		return
+	}
+	for _, j := range imported {
+		recordRevision(nil, j, userID, 0)
+		if j.Moderation.Visible() {
+			published(j)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ImportResult{Jobs: imported})
+}
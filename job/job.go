@@ -2,10 +2,25 @@ package job
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
-	"strings"
+	"slices"
+	"sort"
+	"sync"
+	"talant/application"
+	"talant/auth"
+	"talant/company"
+	"talant/dedup"
+	"talant/moderation"
+	"talant/problem"
+	"talant/quota"
+	"talant/request"
+	"talant/school"
+	"talant/storage"
+	"talant/trash"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -15,16 +30,123 @@ type Job struct {
 	Id string `json:"id"`
 	// UserID - ID пользователя, создавшего вакансию (берется из куки)
 	UserID      string `json:"user_id"` // НОВОЕ ПОЛЕ: Связь с пользователем
-	Title       string `json:"title"`
-	Company     string `json:"company"` // ИСПРАВЛЕНО: Добавлена закрывающая кавычка
-	School      string `json:"school"`
-	Description string `json:"description"`
-	Salary      string `json:"salary"`
-	Skills      string `json:"skills"`
+	Title       string `json:"title" validate:"trim,required,max=200"`
+	Company     string `json:"company" validate:"trim,max=200"` // ИСПРАВЛЕНО: Добавлена закрывающая кавычка
+	School      string `json:"school" validate:"trim,max=200"`
+	Description string `json:"description" validate:"trim,required,max=10000"`
+	// Salary - зарплата текстом для показа, SalaryMin и SalaryMax - вилка числами
+	// для поиска и подбора; 0 - граница не указана
+	Salary    string `json:"salary" validate:"trim,max=100"`
+	SalaryMin int    `json:"salary_min,omitempty" validate:"min=0,max=10000000"`
+	SalaryMax int    `json:"salary_max,omitempty" validate:"min=0,max=10000000"`
+	Skills    string `json:"skills" validate:"trim,max=1000"`
+	// JobType - тип занятости: full, part, remote, internship
+	JobType string `json:"job_type" validate:"oneof=full part remote internship"`
+
+	Logo *storage.File `json:"logo,omitempty"`
+	// AcceptsMinors - вакансия открыта для кандидатов младше 18 лет
+	AcceptsMinors bool `json:"accepts_minors"`
+	// ExpiresAt - окончание публикации, после него вакансия не показывается в общем списке
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	ExpiryNotified bool       `json:"expiry_notified,omitempty"`
+
+	// CompanyID - компания, от имени которой опубликована вакансия. Вакансией
+	// управляет автор и любой участник команды компании, см. ManagedBy
+	CompanyID string `json:"company_id,omitempty"`
+	// SchoolID - заведение из справочника, к которому приведено поле School
+	SchoolID string `json:"school_id,omitempty"`
+
+	// Featured - вакансия выделена на доске и занимает слот из квоты автора
+	Featured  bool      `json:"featured,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Moderation - результат проверки текста; пока вакансия не одобрена,
+	// ее видят только те, кто ею управляет
+	Moderation moderation.Status `json:"moderation"`
+	// Duplicates - похожие действующие вакансии, найденные при последнем сохранении
+	Duplicates []dedup.Match `json:"duplicates,omitempty"`
+	// Version растет с каждой правкой вакансии и отдается в ETag, см. checkIfMatch
+	Version int `json:"version"`
+	// DeletedAt - время удаления; заполнено только у вакансий в корзине
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// Input - поля вакансии в запросах создания и замены (PUT)
+type Input struct {
+	Title         string `json:"title"`
+	Company       string `json:"company"`
+	CompanyID     string `json:"company_id"`
+	School        string `json:"school"`
+	SchoolID      string `json:"school_id"`
+	Description   string `json:"description"`
+	Salary        string `json:"salary"`
+	SalaryMin     int    `json:"salary_min"`
+	SalaryMax     int    `json:"salary_max"`
+	Skills        string `json:"skills"`
+	JobType       string `json:"job_type"`
+	AcceptsMinors bool   `json:"accepts_minors"`
+	// ExpiresAt - последний день публикации в формате YYYY-MM-DD
+	ExpiresAt string `json:"expires_at" validate:"date"`
+	Featured  bool   `json:"featured"`
 }
 
 var db string = "job.json"
 
+// ManagedBy сообщает, может ли пользователь управлять вакансией
+func (j Job) ManagedBy(userID string) bool {
+	return j.UserID == userID || company.IsMember(j.CompanyID, userID)
+}
+
+// resolveCompany проверяет, что пользователь состоит в компании, и возвращает ее
+func resolveCompany(companyID, userID string) (*company.Company, int, string) {
+	c, err := company.Get(companyID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, "Error loading companies"
+	}
+	if c == nil {
+		return nil, http.StatusBadRequest, "Unknown company_id"
+	}
+	if c.RoleOf(userID) == "" {
+		return nil, http.StatusForbidden, "You are not a member of this company"
+	}
+	return c, http.StatusOK, ""
+}
+
+// jobTypes - допустимые типы занятости из правила oneof у Job.JobType
+var jobTypes = request.Options(Job{}, "JobType")
+
+// validateJob проверяет вакансию по правилам из тегов validate, см. request.Validate,
+// обрезает пробелы в текстовых полях и сверяет границы вилки зарплаты
+func validateJob(j *Job) error {
+	var salaryErr error
+	if j.SalaryMax > 0 && j.SalaryMin > j.SalaryMax {
+		salaryErr = request.Invalid("salary_max", "must not be less than salary_min")
+	}
+	return request.Join(request.Validate(j), salaryErr)
+}
+
+// ValidJobType сообщает, допустим ли тип занятости; пустой - не указан
+func ValidJobType(t string) bool {
+	return t == "" || slices.Contains(jobTypes, t)
+}
+
+// publishHooks вызываются после сохранения новой или измененной вакансии
+var publishHooks []func(Job)
+
+// OnPublish регистрирует функцию, которая будет вызвана при публикации вакансии
+func OnPublish(fn func(Job)) {
+	publishHooks = append(publishHooks, fn)
+}
+
+func published(job Job) {
+	for _, fn := range publishHooks {
+		go fn(job)
+	}
+}
+
+// mu сериализует изменения job.json
+var mu sync.Mutex
+
 func LoadJobs() ([]Job, error) {
 	data, err := os.ReadFile(db)
 	if err != nil {
@@ -62,119 +184,268 @@ func SaveJobs(jobs []Job) error {
 }
 func UpdateHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	userIDCookie, err := r.Cookie("id_cookie")
+	currentUserID, err := auth.UserIDFromRequest(r)
 	if err != nil {
-		http.Error(w, "Unauthorized: Missing user ID cookie", http.StatusUnauthorized)
+		auth.Error(w, err)
 		return
 	}
-	currentUserID := userIDCookie.Value
 
-	jobID := strings.TrimPrefix(r.URL.Path, "/job/")
+	jobID := r.PathValue("id")
 	if jobID == "" {
-		http.Error(w, "Missing job ID", http.StatusBadRequest)
+		problem.Write(w, http.StatusBadRequest, problem.CodeMissingID, "Missing job ID")
 		return
 	}
 
-	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad form", http.StatusBadRequest)
+	var in Input
+	sent, err := request.Decode(w, r, &in)
+	if err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if err := request.Validate(&in); err != nil {
+		request.WriteError(w, err)
 		return
 	}
 
+	mu.Lock()
+	defer mu.Unlock()
 	jobs, err := LoadJobs()
 	if err != nil {
-		http.Error(w, "Load error", http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Load error")
+		return
+	}
+	i, ok := editableJob(w, r, jobs, jobID, currentUserID)
+	if !ok {
+		return
+	}
+
+	// PUT заменяет все редактируемые поля; частичное изменение - PatchHandler
+	edited := jobs[i]
+	// Без поля company_id вакансия остается за прежней компанией
+	if sent.Has("company_id") {
+		edited.CompanyID = in.CompanyID
+	}
+	edited.Company = in.Company
+	edited.Title = in.Title
+	edited.SchoolID, edited.School = in.SchoolID, in.School
+	edited.Description = in.Description
+	edited.Salary = in.Salary
+	edited.SalaryMin, edited.SalaryMax = in.SalaryMin, in.SalaryMax
+	edited.Skills = in.Skills
+	edited.JobType = in.JobType
+	edited.AcceptsMinors = in.AcceptsMinors
+	edited.ExpiresAt = parseExpiresAt(in.ExpiresAt)
+	if sent.Has("featured") {
+		edited.Featured = in.Featured
+	}
+
+	saved, ok := commitEdit(w, jobs, i, edited, currentUserID, 0)
+	if !ok {
 		return
 	}
 
-	updated := false
+	w.Header().Set("ETag", saved.ETag())
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Updated"))
+}
 
+// editableJob находит вакансию для правки и проверяет права и условие If-Match.
+// Возвращает индекс вакансии в jobs; при ошибке ответ уже записан.
+func editableJob(w http.ResponseWriter, r *http.Request, jobs []Job, jobID, userID string) (int, bool) {
 	for i := range jobs {
-		if jobs[i].Id == jobID {
-			if jobs[i].UserID != currentUserID {
-				http.Error(w, "Forbidden: cannot edit other user's job", http.StatusForbidden)
-				return
-			}
-
-			jobs[i].Title = r.FormValue("title")
-			jobs[i].Company = r.FormValue("company")
-			jobs[i].School = r.FormValue("school")
-			jobs[i].Description = r.FormValue("description")
-			jobs[i].Salary = r.FormValue("salary")
-			jobs[i].Skills = r.FormValue("skills")
+		if jobs[i].Id != jobID {
+			continue
+		}
+		if !jobs[i].ManagedBy(userID) {
+			problem.Write(w, http.StatusForbidden, problem.CodeForbidden, "Cannot edit other user's job")
+			return 0, false
+		}
+		if !checkIfMatch(w, r, jobs[i]) {
+			return 0, false
+		}
+		return i, true
+	}
+	problem.Write(w, http.StatusNotFound, problem.CodeJobNotFound, "Job not found")
+	return 0, false
+}
 
-			updated = true
-			break
+// commitEdit проверяет измененную копию edited вакансии jobs[i] и сохраняет ее:
+// компания, заведение, дубликаты, квота и модерация - как при создании.
+// restoredFrom - номер ревизии, из которой восстановлена правка, или 0.
+// Возвращает сохраненную вакансию; при ошибке ответ уже записан.
+func commitEdit(w http.ResponseWriter, jobs []Job, i int, edited Job, userID string, restoredFrom int) (Job, bool) {
+	// Использование квоты считается до изменения вакансии
+	now := time.Now()
+	before, used := jobs[i], usageOf(jobs, jobs[i].UserID, now)
+
+	if err := validateJob(&edited); err != nil {
+		request.WriteError(w, err)
+		return Job{}, false
+	}
+	if edited.CompanyID != "" {
+		c, status, msg := resolveCompany(edited.CompanyID, userID)
+		if status != http.StatusOK {
+			problem.Write(w, status, problem.CodeFor(status), msg)
+			return Job{}, false
 		}
+		edited.Company = c.Name
+	} else if edited.UserID != userID {
+		problem.Write(w, http.StatusForbidden, problem.CodeForbidden, "Only the author can detach a job from the company")
+		return Job{}, false
 	}
 
-	if !updated {
-		http.Error(w, "Job not found", http.StatusNotFound)
-		return
+	var err error
+	edited.SchoolID, edited.School, err = school.Resolve(edited.SchoolID, edited.School)
+	if errors.Is(err, school.ErrUnknownSchool) {
+		request.WriteError(w, request.Invalid("school_id", err.Error()))
+		return Job{}, false
+	}
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Load error")
+		return Job{}, false
+	}
+	if !sameTime(before.ExpiresAt, edited.ExpiresAt) {
+		edited.ExpiryNotified = false
+	}
+
+	dups, ok := checkDuplicates(w, edited, jobs)
+	if !ok {
+		return Job{}, false
 	}
+	edited.Duplicates = dups
 
+	// Продление истекшей вакансии и выделение расходуют квоту ее автора
+	wasActive, wasFeatured := !before.Expired(now), before.Featured && !before.Expired(now)
+	isActive := !edited.Expired(now)
+	if _, err := quota.Reserve(edited.UserID, used, quota.Request{
+		Activate: isActive && !wasActive,
+		Feature:  isActive && edited.Featured && !wasFeatured,
+	}); err != nil {
+		quota.Error(w, err)
+		return Job{}, false
+	}
+
+	edited.Moderation, err = moderation.Submit(moderationContent(edited))
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Moderation error")
+		return Job{}, false
+	}
+	edited.Moderation.Hidden = before.Moderation.Hidden
+	edited.Version = before.nextVersion()
+
+	jobs[i] = edited
 	if err := SaveJobs(jobs); err != nil {
-		http.Error(w, "Save error", http.StatusInternalServerError)
-		return
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Save error")
+		return Job{}, false
 	}
+	if err := application.SetCompany(edited.Id, edited.CompanyID); err != nil {
+		fmt.Printf("Ошибка обновления откликов: %v\n", err)
+	}
+	recordRevision(&before, edited, userID, restoredFrom)
+	if edited.Moderation.Visible() {
+		published(edited)
+	}
+	return edited, true
+}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Updated"))
+// fromInput строит новую вакансию автора userID из полей запроса; проверка - validateJob
+func fromInput(in Input, userID string) Job {
+	return Job{
+		Id:          uuid.New().String(), // УНИКАЛЬНЫЙ ID ВАКАНСИИ
+		UserID:      userID,              // ID создателя
+		Title:       in.Title,
+		Company:     in.Company,
+		School:      in.School,
+		Description: in.Description,
+		Salary:      in.Salary,
+		SalaryMin:   in.SalaryMin,
+		SalaryMax:   in.SalaryMax,
+		Skills:      in.Skills,
+		JobType:     in.JobType,
+
+		AcceptsMinors: in.AcceptsMinors,
+		CompanyID:     in.CompanyID,
+		Featured:      in.Featured,
+		ExpiresAt:     parseExpiresAt(in.ExpiresAt),
+		CreatedAt:     time.Now().UTC(),
+		Version:       1,
+	}
 }
 
 func CreateHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	userIDCookie, err := r.Cookie("id_cookie")
+	userID, err := auth.UserIDFromRequest(r)
 	if err != nil {
-		http.Error(w, "Unauthorized: Missing user ID cookie", http.StatusUnauthorized)
+		auth.Error(w, err)
 		return
 	}
-	userID := userIDCookie.Value
 
-	err = r.ParseForm()
-	if err != nil {
-		http.Error(w, "Error parsing form: "+err.Error(), http.StatusBadRequest)
+	var in Input
+	if _, err := request.Decode(w, r, &in); err != nil {
+		request.WriteError(w, err)
 		return
 	}
 
-	// 1. Проверка на ограничение 1 вакансией
-	jobs, err := LoadJobs()
-	if err != nil {
-		http.Error(w, "Error loading jobs: "+err.Error(), http.StatusInternalServerError)
+	newJob := fromInput(in, userID)
+
+	// Ошибки в сроке публикации показываются вместе с остальными
+	if err := request.Join(request.Validate(&in), validateJob(&newJob)); err != nil {
+		request.WriteError(w, err)
 		return
 	}
-
-	// Если нужна проверка "не более 1 вакансии на пользователя"
-	for _, job := range jobs {
-		if job.UserID == userID {
-			http.Error(w, "User can only create one job", http.StatusConflict)
+	if newJob.CompanyID != "" {
+		c, status, msg := resolveCompany(newJob.CompanyID, userID)
+		if status != http.StatusOK {
+			problem.Write(w, status, problem.CodeFor(status), msg)
 			return
 		}
+		newJob.Company = c.Name
 	}
-
-	// 2. Генерация уникального Job ID
-	jobID := uuid.New().String()
-
-	newJob := Job{
-		Id:          jobID,  // УНИКАЛЬНЫЙ ID ВАКАНСИИ
-		UserID:      userID, // ID создателя
-		Title:       r.FormValue("title"),
-		Company:     r.FormValue("company"),
-		School:      r.FormValue("school"),
-		Description: r.FormValue("description"),
-		Salary:      r.FormValue("salary"),
-		Skills:      r.FormValue("skills"),
+	newJob.SchoolID, newJob.School, err = school.Resolve(in.SchoolID, newJob.School)
+	if errors.Is(err, school.ErrUnknownSchool) {
+		request.WriteError(w, request.Invalid("school_id", err.Error()))
+		return
+	}
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading schools: "+err.Error())
+		return
 	}
 
-	if newJob.Title == "" || newJob.Description == "" {
-		http.Error(w, "Title and Description are required", http.StatusBadRequest)
+	// Проверка квоты и сохранение идут под одной блокировкой,
+	// поэтому параллельные запросы не превысят лимит
+	mu.Lock()
+	defer mu.Unlock()
+	jobs, err := LoadJobs()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading jobs: "+err.Error())
+		return
+	}
+	dups, ok := checkDuplicates(w, newJob, jobs)
+	if !ok {
+		return
+	}
+	newJob.Duplicates = dups
+	reservation, err := quota.Reserve(userID, usageOf(jobs, userID, time.Now()), quota.Request{
+		Create:   true,
+		Activate: !newJob.Expired(time.Now()),
+		Feature:  newJob.Featured,
+	})
+	if err != nil {
+		quota.Error(w, err)
+		return
+	}
+	newJob.Moderation, err = moderation.Submit(moderationContent(newJob))
+	if err != nil {
+		reservation.Cancel()
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Moderation error: "+err.Error())
 		return
 	}
 
@@ -182,31 +453,37 @@ func CreateHandler(w http.ResponseWriter, r *http.Request) {
 
 	err = SaveJobs(jobs)
 	if err != nil {
-		http.Error(w, "Error saving job: "+err.Error(), http.StatusInternalServerError)
+		reservation.Cancel()
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving job: "+err.Error())
 		return
 	}
+	recordRevision(nil, newJob, userID, 0)
+	if newJob.Moderation.Visible() {
+		published(newJob)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", newJob.ETag())
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(newJob)
 }
 
 func OpenHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	// ИСПРАВЛЕНИЕ: Получаем ID вакансии из URL (например, /job/123-abc)
-	jobID := strings.TrimPrefix(r.URL.Path, "/job/")
-	if jobID == "" || jobID == "job" {
-		http.Error(w, "Missing job ID in URL path", http.StatusBadRequest)
+	// ID вакансии из шаблона маршрута: /job/{id} или /api/v1/jobs/{id}
+	jobID := r.PathValue("id")
+	if jobID == "" {
+		problem.Write(w, http.StatusBadRequest, problem.CodeMissingID, "Missing job ID in URL path")
 		return
 	}
 
 	jobs, err := LoadJobs()
 	if err != nil {
-		http.Error(w, "Error loading jobs: "+err.Error(), http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading jobs: "+err.Error())
 		return
 	}
 
@@ -220,12 +497,21 @@ func OpenHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if foundJob == nil {
-		http.Error(w, "Job not found", http.StatusNotFound)
+		problem.Write(w, http.StatusNotFound, problem.CodeJobNotFound, "Job not found")
 		return
 	}
+	// Непроверенную вакансию видят только те, кто ею управляет, и модераторы
+	if !foundJob.Moderation.Visible() {
+		viewerID, _ := auth.UserIDFromRequest(r)
+		if !foundJob.ManagedBy(viewerID) && !auth.IsModerator(viewerID) {
+			problem.Write(w, http.StatusNotFound, problem.CodeJobNotFound, "Job not found")
+			return
+		}
+	}
 
 	// ЭТО ИСПРАВЛЯЕТ ПРОБЛЕМУ "НЕЛЬЗЯ РАЗВЕРНУТЬ"
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", foundJob.ETag())
 	json.NewEncoder(w).Encode(foundJob)
 }
 
@@ -233,55 +519,73 @@ func OpenHandler(w http.ResponseWriter, r *http.Request) {
 
 func GetAllHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
 	jobs, err := LoadJobs()
 	if err != nil {
-		http.Error(w, "Error loading jobs: "+err.Error(), http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading jobs: "+err.Error())
 		return
 	}
 
+	// Вакансии с истекшим сроком публикации в общий список не попадают
+	now := time.Now()
+	active := []Job{}
+	for _, job := range jobs {
+		if job.Listed(now) {
+			active = append(active, job)
+		}
+	}
+	// Выделенные вакансии показываются первыми
+	sort.SliceStable(active, func(i, j int) bool { return active[i].Featured && !active[j].Featured })
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(jobs)
+	json.NewEncoder(w).Encode(active)
 }
 
+// DeleteHandler удаляет вакансию в корзину; через trash.Retention она удаляется навсегда
 func DeleteHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	userIDCookie, err := r.Cookie("id_cookie")
+	currentUserID, err := auth.UserIDFromRequest(r)
 	if err != nil {
-		http.Error(w, "Unauthorized: Missing user ID cookie", http.StatusUnauthorized)
+		auth.Error(w, err)
 		return
 	}
-	currentUserID := userIDCookie.Value
 
-	// ИСПРАВЛЕНИЕ: Получаем ID вакансии из URL (например, /job/123-abc)
-	jobID := strings.TrimPrefix(r.URL.Path, "/job/")
-	if jobID == "" || jobID == "job" {
-		http.Error(w, "Missing job ID in URL path", http.StatusBadRequest)
+	// ID вакансии из шаблона маршрута: /job/{id} или /api/v1/jobs/{id}
+	jobID := r.PathValue("id")
+	if jobID == "" {
+		problem.Write(w, http.StatusBadRequest, problem.CodeMissingID, "Missing job ID in URL path")
 		return
 	}
 
+	mu.Lock()
+	defer mu.Unlock()
 	jobs, err := LoadJobs()
 	if err != nil {
-		http.Error(w, "Error loading jobs: "+err.Error(), http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading jobs: "+err.Error())
 		return
 	}
 
 	var updatedJobs []Job
 	var found bool
 	var unauthorized bool
+	var deleted Job
 
 	// ИСПРАВЛЕНИЕ: Проверяем, что ID вакансии совпадает, И что текущий пользователь — создатель
 	for _, job := range jobs {
 		if job.Id == jobID {
-			if job.UserID == currentUserID { // Проверка прав
+			if job.ManagedBy(currentUserID) { // Проверка прав
+				if !checkIfMatch(w, r, job) {
+					return
+				}
 				found = true
+				deleted = job
 				// Не добавляем в updatedJobs (удаляем)
 			} else {
 				unauthorized = true
@@ -293,18 +597,32 @@ func DeleteHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if unauthorized {
-		http.Error(w, "Forbidden: You can only delete your own jobs", http.StatusForbidden)
+		problem.Write(w, http.StatusForbidden, problem.CodeForbidden, "You can only delete your own jobs")
 		return
 	}
 
 	if !found {
-		http.Error(w, "Job not found", http.StatusNotFound)
+		problem.Write(w, http.StatusNotFound, problem.CodeJobNotFound, "Job not found")
 		return
 	}
 
+	// Вакансия переносится в корзину, откуда ее можно восстановить, см. UndeleteHandler
+	now := time.Now()
+	deleted.DeletedAt = &now
+	err = trash.Put(trash.Item{
+		Kind: trash.KindJob, ObjectID: deleted.Id, OwnerID: deleted.UserID, CompanyID: deleted.CompanyID,
+		Title: deleted.Title, DeletedBy: currentUserID, DeletedAt: now,
+	}, deleted)
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving trash: "+err.Error())
+		return
+	}
 	err = SaveJobs(updatedJobs)
 	if err != nil {
-		http.Error(w, "Error saving jobs: "+err.Error(), http.StatusInternalServerError)
+		if err := trash.Remove(trash.KindJob, deleted.Id); err != nil {
+			fmt.Printf("Ошибка отмены удаления в корзину: %v\n", err)
+		}
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving jobs: "+err.Error())
 		return
 	}
 
@@ -313,27 +631,26 @@ func DeleteHandler(w http.ResponseWriter, r *http.Request) {
 
 func MyjobHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	userIDCookie, err := r.Cookie("id_cookie")
+	currentUserID, err := auth.UserIDFromRequest(r)
 	if err != nil {
-		http.Error(w, "Unauthorized: Missing user ID cookie", http.StatusUnauthorized)
+		auth.Error(w, err)
 		return
 	}
-	currentUserID := userIDCookie.Value
 
 	jobs, err := LoadJobs()
 	if err != nil {
-		http.Error(w, "Error loading jobs: "+err.Error(), http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading jobs: "+err.Error())
 		return
 	}
 
-	// ИСПРАВЛЕНИЕ: Ищем ВСЕ вакансии, созданные текущим пользователем
-	var userJobs []Job
+	// Ищем ВСЕ вакансии, которыми управляет текущий пользователь: свои и вакансии его компаний
+	userJobs := []Job{}
 	for _, job := range jobs {
-		if currentUserID == job.UserID { // Ищем по UserID
+		if job.ManagedBy(currentUserID) {
 			userJobs = append(userJobs, job)
 		}
 	}
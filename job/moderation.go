@@ -0,0 +1,102 @@
+package job
+
+import (
+	"talant/moderation"
+	"time"
+)
+
+// moderationContent - проверяемый текст вакансии
+func moderationContent(j Job) moderation.Content {
+	return moderation.Content{
+		Kind:     moderation.KindJob,
+		ID:       j.Id,
+		AuthorID: j.UserID,
+		Title:    j.Title,
+		Fields: map[string]string{
+			"title":       j.Title,
+			"company":     j.Company,
+			"description": j.Description,
+			"salary":      j.Salary,
+			"skills":      j.Skills,
+		},
+	}
+}
+
+// Listed сообщает, показывается ли вакансия в общих списках: срок публикации
+// не истек и модерация пройдена
+func (j Job) Listed(now time.Time) bool {
+	return !j.Expired(now) && j.Moderation.Visible()
+}
+
+// ApplyModeration сохраняет решение модератора в вакансии. Одобренная вакансия
+// публикуется так же, как прошедшая проверки сразу.
+func ApplyModeration(jobID string, s moderation.Status) error {
+	mu.Lock()
+	jobs, err := LoadJobs()
+	if err != nil {
+		mu.Unlock()
+		return err
+	}
+	var found *Job
+	for i := range jobs {
+		if jobs[i].Id == jobID {
+			s.Hidden = jobs[i].Moderation.Hidden
+			jobs[i].Moderation = s
+			found = &jobs[i]
+			break
+		}
+	}
+	if found == nil {
+		mu.Unlock()
+		return nil
+	}
+	err = SaveJobs(jobs)
+	mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if s.Visible() {
+		published(*found)
+	}
+	return nil
+}
+
+// moderateUnchecked отправляет на проверку вакансию, сохраненную до появления
+// модерации: пустое состояние не считается одобрением. Возвращает true, если
+// состояние изменилось.
+func moderateUnchecked(j *Job) (bool, error) {
+	if j.Moderation.State != "" {
+		return false, nil
+	}
+	status, err := moderation.Submit(moderationContent(*j))
+	if err != nil {
+		return false, err
+	}
+	status.Hidden = j.Moderation.Hidden
+	j.Moderation = status
+	return true, nil
+}
+
+// ModerateExisting проверяет сохраненные вакансии без состояния модерации.
+// Вакансии с замечаниями скрываются до решения модератора, повторный вызов
+// ничего не меняет.
+func ModerateExisting() error {
+	mu.Lock()
+	defer mu.Unlock()
+	jobs, err := LoadJobs()
+	if err != nil {
+		return err
+	}
+	changed := false
+	for i := range jobs {
+		ok, err := moderateUnchecked(&jobs[i])
+		if err != nil {
+			return err
+		}
+		changed = changed || ok
+	}
+	if !changed {
+		return nil
+	}
+	return SaveJobs(jobs)
+}
@@ -0,0 +1,178 @@
+package job
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"talant/auth"
+	"talant/problem"
+	"talant/request"
+	"time"
+)
+
+// patchFields - поля вакансии, которые можно менять через PATCH
+var patchFields = map[string]bool{
+	"title": true, "company": true, "company_id": true, "school": true, "school_id": true,
+	"description": true, "salary": true, "salary_min": true, "salary_max": true, "skills": true, "job_type": true,
+	"accepts_minors": true, "expires_at": true, "featured": true,
+}
+
+// version возвращает номер версии вакансии; вакансии, сохраненные до
+// нумерации версий, считаются первой версией
+func (j Job) version() int {
+	if j.Version < 1 {
+		return 1
+	}
+	return j.Version
+}
+
+// nextVersion - номер версии вакансии после очередной правки
+func (j Job) nextVersion() int {
+	return j.version() + 1
+}
+
+// ETag возвращает тег текущей версии вакансии. Тег меняется при правках автора
+// и команды, но не при решениях модераторов и продлении уведомлений.
+func (j Job) ETag() string {
+	return `"` + strconv.Itoa(j.version()) + `"`
+}
+
+// checkIfMatch проверяет заголовок If-Match. Без заголовка правка разрешена;
+// если ни один тег не совпал с текущим, отвечает 412 и возвращает false.
+func checkIfMatch(w http.ResponseWriter, r *http.Request, j Job) bool {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		// Слабые теги (W/"...") по RFC 9110 не подходят для If-Match
+		if tag = strings.TrimSpace(tag); tag == "*" || tag == j.ETag() {
+			return true
+		}
+	}
+	w.Header().Set("ETag", j.ETag())
+	problem.Write(w, http.StatusPreconditionFailed, problem.CodePreconditionFailed, "The job has been modified, reload it and retry")
+	return false
+}
+
+// PatchHandler частично изменяет вакансию (PATCH /job/{id}). Тело - JSON Merge Patch
+// (RFC 7396): переданные поля заменяются, null сбрасывает поле, остальные не меняются.
+func PatchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+		problem.Write(w, http.StatusUnsupportedMediaType, problem.CodeUnsupportedMediaType, "Use application/merge-patch+json")
+		return
+	}
+
+	var patch map[string]json.RawMessage
+	if _, err := request.DecodeJSON(w, r, &patch); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	for name := range patch {
+		if !patchFields[name] {
+			request.WriteError(w, request.Invalid(name, "unknown field or not editable"))
+			return
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	jobs, err := LoadJobs()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading jobs: "+err.Error())
+		return
+	}
+	i, ok := editableJob(w, r, jobs, r.PathValue("id"), userID)
+	if !ok {
+		return
+	}
+	edited, err := mergePatch(jobs[i], patch)
+	if err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	saved, ok := commitEdit(w, jobs, i, edited, userID, 0)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", saved.ETag())
+	json.NewEncoder(w).Encode(saved)
+}
+
+// mergePatch применяет merge patch к копии вакансии. Поля вакансии плоские,
+// поэтому вложенные объекты сливать не нужно: значение поля заменяется целиком.
+func mergePatch(j Job, patch map[string]json.RawMessage) (Job, error) {
+	data, err := json.Marshal(j)
+	if err != nil {
+		return Job{}, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return Job{}, err
+	}
+	for name, value := range patch {
+		if name == "expires_at" {
+			continue
+		}
+		if string(value) == "null" {
+			delete(fields, name)
+		} else {
+			fields[name] = value
+		}
+	}
+	// Новое название заведения без school_id отвязывает вакансию от справочника
+	if _, sent := patch["school"]; sent {
+		if _, sent := patch["school_id"]; !sent {
+			delete(fields, "school_id")
+		}
+	}
+
+	data, err = json.Marshal(fields)
+	if err != nil {
+		return Job{}, err
+	}
+	var edited Job
+	if err := json.Unmarshal(data, &edited); err != nil {
+		return Job{}, request.FromJSON(err)
+	}
+	if value, sent := patch["expires_at"]; sent {
+		if edited.ExpiresAt, err = patchExpiresAt(value); err != nil {
+			return Job{}, err
+		}
+	}
+	return edited, nil
+}
+
+// patchExpiresAt разбирает expires_at из merge patch: дату в формате формы
+// (YYYY-MM-DD), время RFC 3339, как в ответе GET, или null - без срока
+func patchExpiresAt(value json.RawMessage) (*time.Time, error) {
+	var s *string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return nil, request.Invalid("expires_at", "must be a string or null")
+	}
+	if s == nil || *s == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, *s); err == nil {
+		return &t, nil
+	}
+	if t := parseExpiresAt(*s); t != nil {
+		return t, nil
+	}
+	return nil, request.Invalid("expires_at", "must be in YYYY-MM-DD format")
+}
@@ -0,0 +1,53 @@
+package job
+
+import (
+	"encoding/json"
+	"net/http"
+	"talant/auth"
+	"talant/problem"
+	"talant/quota"
+	"time"
+)
+
+// usageOf считает активные и выделенные вакансии автора для проверки квоты
+func usageOf(jobs []Job, userID string, now time.Time) quota.Usage {
+	var u quota.Usage
+	for _, j := range jobs {
+		if j.UserID != userID || j.Expired(now) {
+			continue
+		}
+		u.Active++
+		if j.Featured {
+			u.Featured++
+		}
+	}
+	return u
+}
+
+// QuotaHandler показывает план текущего пользователя и остаток квоты на публикации (GET /quota)
+func QuotaHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	jobs, err := LoadJobs()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading jobs: "+err.Error())
+		return
+	}
+	report, err := quota.Status(userID, usageOf(jobs, userID, time.Now()))
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error checking quota")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,33 @@
+package job
+
+// ReportTarget возвращает автора вакансии для жалобы или "", если вакансии нет
+// или она не показывается другим пользователям
+func ReportTarget(jobID, reporterID string) (string, error) {
+	jobs, err := LoadJobs()
+	if err != nil {
+		return "", err
+	}
+	for _, j := range jobs {
+		if j.Id == jobID && j.Moderation.Visible() {
+			return j.UserID, nil
+		}
+	}
+	return "", nil
+}
+
+// SetHidden скрывает вакансию по жалобам или возвращает ее в показ
+func SetHidden(jobID string, hidden bool) error {
+	mu.Lock()
+	defer mu.Unlock()
+	jobs, err := LoadJobs()
+	if err != nil {
+		return err
+	}
+	for i := range jobs {
+		if jobs[i].Id == jobID {
+			jobs[i].Moderation.Hidden = hidden
+			return SaveJobs(jobs)
+		}
+	}
+	return nil
+}
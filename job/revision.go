@@ -0,0 +1,250 @@
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"talant/application"
+	"talant/auth"
+	"talant/problem"
+	"talant/revision"
+	"time"
+)
+
+// revisionOmit - служебные поля вакансии, которые не входят в ревизии: срок публикации
+// и выделение расходуют квоту и меняются только правкой, а не восстановлением
+var revisionOmit = []string{"id", "user_id", "logo", "expires_at", "expiry_notified",
+	"featured", "created_at", "moderation", "duplicates", "version"}
+
+// snapshot - ревизия с текущим содержимым вакансии
+func snapshot(j Job, authorID string, at time.Time) (revision.Revision, error) {
+	content, err := revision.Snapshot(j, revisionOmit...)
+	if err != nil {
+		return revision.Revision{}, err
+	}
+	return revision.Revision{Kind: revision.KindJob, ObjectID: j.Id, AuthorID: authorID, CreatedAt: at.UTC(), Content: content}, nil
+}
+
+// currentRevision возвращает номер ревизии, соответствующей сохраненной вакансии.
+// Для вакансий, созданных до ведения истории, сначала сохраняется исходная ревизия.
+func currentRevision(j Job) (int, error) {
+	base, err := snapshot(j, j.UserID, j.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	if err := revision.Baseline(base); err != nil {
+		return 0, err
+	}
+	rev, err := revision.Get(revision.KindJob, j.Id, 0)
+	if err != nil || rev == nil {
+		return 0, err
+	}
+	return rev.Number, nil
+}
+
+// recordRevision сохраняет правку вакансии. before - состояние до правки,
+// nil для новой вакансии; restoredFrom - номер восстановленной ревизии или 0.
+// Ошибка истории не отменяет уже сохраненную правку, поэтому только пишется в лог.
+func recordRevision(before *Job, after Job, authorID string, restoredFrom int) {
+	if before != nil {
+		if _, err := currentRevision(*before); err != nil {
+			fmt.Printf("Ошибка сохранения ревизии: %v\n", err)
+			return
+		}
+	}
+	rev, err := snapshot(after, authorID, time.Now())
+	if err == nil {
+		rev.RestoredFrom = restoredFrom
+		_, err = revision.Record(rev)
+	}
+	if err != nil {
+		fmt.Printf("Ошибка сохранения ревизии: %v\n", err)
+	}
+}
+
+// managedJob находит вакансию, которой управляет пользователь или которую проверяет модератор
+func managedJob(w http.ResponseWriter, r *http.Request) (*Job, bool) {
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return nil, false
+	}
+	jobs, err := LoadJobs()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading jobs: "+err.Error())
+		return nil, false
+	}
+	for i := range jobs {
+		if jobs[i].Id != r.PathValue("id") {
+			continue
+		}
+		if !jobs[i].ManagedBy(userID) && !auth.IsModerator(userID) {
+			problem.Write(w, http.StatusForbidden, problem.CodeForbidden, "Only the job owner can see its history")
+			return nil, false
+		}
+		return &jobs[i], true
+	}
+	problem.Write(w, http.StatusNotFound, problem.CodeJobNotFound, "Job not found")
+	return nil, false
+}
+
+// RevisionsHandler возвращает историю правок вакансии (GET /job/{id}/revisions)
+func RevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	j, ok := managedJob(w, r)
+	if !ok {
+		return
+	}
+	if _, err := currentRevision(*j); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading revisions")
+		return
+	}
+	revision.WriteList(w, revision.KindJob, j.Id)
+}
+
+// DiffHandler возвращает изменения полей между ревизиями вакансии (GET /job/{id}/diff?from=&to=)
+func DiffHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	j, ok := managedJob(w, r)
+	if !ok {
+		return
+	}
+	revision.WriteDiff(w, r, revision.KindJob, j.Id)
+}
+
+// RestoreHandler восстанавливает содержимое вакансии из ревизии
+// (POST /job/{id}/revisions/{number}/restore). Восстановление - это новая правка:
+// она проходит те же проверки, что и обновление, учитывает If-Match и
+// сохраняется новой ревизией.
+func RestoreHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+	number, err := strconv.Atoi(r.PathValue("number"))
+	if err != nil || number <= 0 {
+		problem.Write(w, http.StatusNotFound, problem.CodeRevisionNotFound, "Revision not found")
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	jobs, err := LoadJobs()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading jobs: "+err.Error())
+		return
+	}
+	i, ok := editableJob(w, r, jobs, r.PathValue("id"), userID)
+	if !ok {
+		return
+	}
+	rev, err := revision.Get(revision.KindJob, jobs[i].Id, number)
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading revisions")
+		return
+	}
+	if rev == nil {
+		problem.Write(w, http.StatusNotFound, problem.CodeRevisionNotFound, "Revision not found")
+		return
+	}
+
+	edited := jobs[i]
+	if err := json.Unmarshal(rev.Content, &edited); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error reading revision")
+		return
+	}
+	// Ревизия могла быть сохранена до нынешних правил проверки: commitEdit
+	// проверяет ее так же, как правку
+	j, ok := commitEdit(w, jobs, i, edited, userID, number)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", j.ETag())
+	json.NewEncoder(w).Encode(j)
+}
+
+// AppliedVersion - ответ AppliedVersionHandler. ChangedSince - вакансию правили после отклика
+type AppliedVersion struct {
+	Revision     int  `json:"revision"`
+	ChangedSince bool `json:"changed_since"`
+	Job          Job  `json:"job"`
+}
+
+// AppliedVersionHandler показывает вакансию в том виде, в котором она была
+// на момент отклика (GET /applications/{id}/job). Доступно обеим сторонам отклика.
+func AppliedVersionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+	app, err := application.Get(r.PathValue("id"))
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading applications")
+		return
+	}
+	if app == nil || (app.CandidateID != userID && !app.ManagedBy(userID)) {
+		problem.Write(w, http.StatusNotFound, problem.CodeApplicationNotFound, "Application not found")
+		return
+	}
+
+	// Отклики, созданные до ведения истории, показывают самую раннюю ревизию
+	number := app.JobRevision
+	if number == 0 {
+		number = 1
+	}
+	rev, err := revision.Get(revision.KindJob, app.JobID, number)
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading revisions")
+		return
+	}
+	if rev == nil {
+		problem.Write(w, http.StatusNotFound, problem.CodeRevisionNotFound, "Job version not found")
+		return
+	}
+	latest, err := revision.Get(revision.KindJob, app.JobID, 0)
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading revisions")
+		return
+	}
+
+	// Служебные поля берутся из текущей вакансии, если ее еще не удалили
+	j := Job{Id: app.JobID, UserID: app.EmployerID}
+	jobs, err := LoadJobs()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading jobs: "+err.Error())
+		return
+	}
+	for _, current := range jobs {
+		if current.Id == app.JobID {
+			j = current
+			break
+		}
+	}
+	if err := json.Unmarshal(rev.Content, &j); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error reading revision")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AppliedVersion{Revision: rev.Number, ChangedSince: latest.Number > rev.Number, Job: j})
+}
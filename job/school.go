@@ -0,0 +1,70 @@
+package job
+
+import (
+	"encoding/json"
+	"net/http"
+	"talant/problem"
+	"talant/school"
+	"time"
+)
+
+// SchoolJob - вакансия на доске заведения
+type SchoolJob struct {
+	Job
+	// Pinned - вакансию закрепили администраторы заведения
+	Pinned bool `json:"pinned"`
+}
+
+// SchoolJobsHandler - доска вакансий заведения (GET /schools/{id}/jobs).
+// Показывает актуальные вакансии для этого заведения и закрепленные его администраторами,
+// без скрытых. Закрепленные идут первыми.
+func SchoolJobsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	list, err := school.Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading schools")
+		return
+	}
+	var s *school.School
+	for i := range list {
+		if list[i].Id == r.PathValue("id") {
+			s = &list[i]
+			break
+		}
+	}
+	if s == nil {
+		problem.Write(w, http.StatusNotFound, problem.CodeSchoolNotFound, "School not found")
+		return
+	}
+
+	jobs, err := LoadJobs()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading jobs: "+err.Error())
+		return
+	}
+
+	now := time.Now()
+	var pinned, rest []SchoolJob
+	for _, j := range jobs {
+		if !j.Listed(now) {
+			continue
+		}
+		switch s.Curation[j.Id] {
+		case school.CurationHidden:
+			continue
+		case school.CurationPinned:
+			pinned = append(pinned, SchoolJob{Job: j, Pinned: true})
+			continue
+		}
+		if school.Belongs(list, *s, j.SchoolID, j.School) {
+			rest = append(rest, SchoolJob{Job: j})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(append(append([]SchoolJob{}, pinned...), rest...))
+}
@@ -0,0 +1,86 @@
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"talant/auth"
+	"talant/problem"
+	"talant/quota"
+	"talant/trash"
+	"time"
+)
+
+// UndeleteHandler восстанавливает вакансию из корзины (POST /trash/job/{id}/restore).
+// Вакансия возвращается с прежним ID, поэтому отклики на нее продолжают работать.
+func UndeleteHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	now := time.Now()
+	item, err := trash.Get(trash.KindJob, r.PathValue("id"), now)
+	if err != nil {
+		trash.Error(w, err)
+		return
+	}
+	var j Job
+	if err := json.Unmarshal(item.Content, &j); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error reading trash")
+		return
+	}
+	if !j.ManagedBy(userID) {
+		problem.Write(w, http.StatusForbidden, problem.CodeForbidden, "Cannot restore other user's job")
+		return
+	}
+
+	jobs, err := LoadJobs()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading jobs: "+err.Error())
+		return
+	}
+	for _, existing := range jobs {
+		if existing.Id == j.Id {
+			problem.Write(w, http.StatusConflict, problem.CodeAlreadyExists, "Job already exists")
+			return
+		}
+	}
+	// Восстановленная вакансия снова занимает место в квоте автора
+	if _, err := quota.Reserve(j.UserID, usageOf(jobs, j.UserID, now), quota.Request{
+		Activate: !j.Expired(now),
+		Feature:  !j.Expired(now) && j.Featured,
+	}); err != nil {
+		quota.Error(w, err)
+		return
+	}
+
+	j.DeletedAt = nil
+	j.Version = j.nextVersion()
+	if _, err := moderateUnchecked(&j); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Moderation error")
+		return
+	}
+	if err := SaveJobs(append(jobs, j)); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving jobs: "+err.Error())
+		return
+	}
+	if err := trash.Remove(trash.KindJob, j.Id); err != nil {
+		fmt.Printf("Ошибка очистки корзины: %v\n", err)
+	}
+	if j.Moderation.Visible() {
+		published(j)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", j.ETag())
+	json.NewEncoder(w).Encode(j)
+}
@@ -3,32 +3,168 @@ package main
 import (
 	"fmt"
 	"net/http"
+	"os"
 	"talant/ankety"
+	"talant/api"
 	"talant/auth"
+	"talant/company"
+	"talant/interview"
 	"talant/job"
+	"talant/match"
+	"talant/messaging"
+	"talant/moderation"
+	"talant/notification"
+	"talant/problem"
+	"talant/quota"
+	"talant/realtime"
+	"talant/report"
+	"talant/revision"
+	"talant/school"
+	"talant/search"
+	"talant/storage"
+	"talant/trash"
 )
 
 func main() {
+	if err := storage.LoadSigningKey(); err != nil {
+		fmt.Println("Ошибка запуска:", err)
+		os.Exit(1)
+	}
+
 	mux := http.NewServeMux()
-	mux.HandleFunc("GET /job/{id}", job.OpenHandler)
-	mux.HandleFunc("POST /createjob", job.CreateHandler)
-	mux.HandleFunc("GET /showjobs", job.GetAllHandler)
-	mux.HandleFunc("GET /myjobs", job.MyjobHandler)
-	mux.HandleFunc("PUT /job/{id}", job.UpdateHandler)
-	mux.HandleFunc("DELETE /job/{id}", job.DeleteHandler)
-
-	mux.HandleFunc("/singin", auth.SingInHandler)
-	mux.HandleFunc("/login", auth.LoaginHandler)
-	mux.HandleFunc("/checkauth", auth.CheckAuthHandler)
-	mux.HandleFunc("/logout", auth.LogOutHandler)
-
-	mux.HandleFunc("/createankety", ankety.CreateHandler)
-	mux.HandleFunc("/showankety", ankety.ShowAnketyHandler)
+	// Пользователи, сессии, вакансии, анкеты и отклики - в /api/v1;
+	// прежние адреса этих маршрутов регистрирует там же api.Register
+	api.Register(mux)
+
+	mux.HandleFunc("GET /quota", job.QuotaHandler)
+	mux.HandleFunc("GET /admin/duplicates", job.DuplicatesHandler)
+	mux.HandleFunc("GET /job/{id}/matches", match.JobMatchesHandler)
+	mux.HandleFunc("GET /recommendations/jobs", match.RecommendJobsHandler)
+
+	mux.HandleFunc("POST /companies", company.CreateHandler)
+	mux.HandleFunc("GET /companies", company.ListHandler)
+	mux.HandleFunc("GET /companies/me", company.MyHandler)
+	mux.HandleFunc("GET /companies/{id}", company.OpenHandler)
+	mux.HandleFunc("PUT /companies/{id}", company.UpdateHandler)
+	mux.HandleFunc("PATCH /companies/{id}", company.UpdateHandler)
+	mux.HandleFunc("POST /companies/{id}/members", company.AddMemberHandler)
+	mux.HandleFunc("PUT /companies/{id}/members/{user_id}", company.MemberHandler)
+	mux.HandleFunc("DELETE /companies/{id}/members/{user_id}", company.MemberHandler)
+	mux.HandleFunc("POST /companies/{id}/attachments/logo", company.UploadLogoHandler)
+	mux.HandleFunc("GET /companies/{id}/attachments/logo", company.LogoHandler)
+	mux.HandleFunc("POST /admin/companies/{id}/verify", company.VerifyHandler)
+	mux.HandleFunc("DELETE /admin/companies/{id}/verify", company.VerifyHandler)
+
+	mux.HandleFunc("GET /schools", school.ListHandler)
+	mux.HandleFunc("POST /schools", school.CreateHandler)
+	mux.HandleFunc("GET /schools/{id}", school.OpenHandler)
+	mux.HandleFunc("PATCH /schools/{id}", school.UpdateHandler)
+	mux.HandleFunc("PUT /schools/{id}/admins/{user_id}", school.AdminHandler)
+	mux.HandleFunc("DELETE /schools/{id}/admins/{user_id}", school.AdminHandler)
+	mux.HandleFunc("GET /schools/{id}/jobs", job.SchoolJobsHandler)
+	mux.HandleFunc("PUT /schools/{id}/jobs/{job_id}", school.CurateHandler)
+	mux.HandleFunc("GET /schools/{id}/candidates", ankety.SchoolCandidatesHandler)
+
+	mux.HandleFunc("POST /searches", search.CreateHandler)
+	mux.HandleFunc("GET /searches", search.ListHandler)
+	mux.HandleFunc("GET /searches/{id}", search.OpenHandler)
+	mux.HandleFunc("PUT /searches/{id}", search.UpdateHandler)
+	mux.HandleFunc("DELETE /searches/{id}", search.DeleteHandler)
+
+	mux.HandleFunc("GET /notifications", notification.ListHandler)
+	mux.HandleFunc("POST /notifications/{id}/read", notification.ReadHandler)
+	mux.HandleFunc("POST /notifications/read-all", notification.ReadHandler)
+	mux.HandleFunc("GET /notifications/preferences", notification.PreferencesHandler)
+	mux.HandleFunc("PUT /notifications/preferences", notification.PreferencesHandler)
+
+	mux.HandleFunc("GET /conversations", messaging.ConversationsHandler)
+	mux.HandleFunc("GET /applications/{id}/messages", messaging.ListHandler)
+	mux.HandleFunc("POST /applications/{id}/messages", messaging.SendHandler)
+	mux.HandleFunc("POST /applications/{id}/messages/read", messaging.ReadHandler)
+	mux.HandleFunc("GET /messages/{id}/attachment", messaging.AttachmentHandler)
+	mux.HandleFunc("POST /users/{id}/block", messaging.BlockHandler)
+	mux.HandleFunc("DELETE /users/{id}/block", messaging.BlockHandler)
+	mux.HandleFunc("GET /blocks", messaging.BlocksHandler)
+
+	mux.HandleFunc("POST /applications/{id}/interviews", interview.ProposeHandler)
+	mux.HandleFunc("GET /interviews", interview.ListHandler)
+	mux.HandleFunc("GET /interviews/{id}", interview.OpenHandler)
+	mux.HandleFunc("POST /interviews/{id}/accept", interview.AcceptHandler)
+	mux.HandleFunc("POST /interviews/{id}/reschedule", interview.RescheduleHandler)
+	mux.HandleFunc("POST /interviews/{id}/cancel", interview.CancelHandler)
+	mux.HandleFunc("GET /interviews/{id}/invite.ics", interview.InviteHandler)
+
+	mux.HandleFunc("GET /moderation/queue", moderation.QueueHandler)
+	mux.HandleFunc("POST /moderation/queue/{id}/{decision}", moderation.DecideHandler)
+
+	mux.HandleFunc("POST /job/{id}/report", report.Handler(report.KindJob))
+	mux.HandleFunc("POST /ankety/{id}/report", report.Handler(report.KindAnkety))
+	mux.HandleFunc("POST /users/{id}/report", report.Handler(report.KindUser))
+	mux.HandleFunc("POST /messages/{id}/report", report.Handler(report.KindMessage))
+	mux.HandleFunc("GET /admin/reports", report.CasesHandler)
+	mux.HandleFunc("GET /admin/reports/{id}", report.CaseHandler)
+	mux.HandleFunc("POST /admin/reports/{id}/resolve", report.ResolveHandler)
+	mux.HandleFunc("GET /admin/users/{id}/sanctions", report.SanctionsHandler)
+	mux.HandleFunc("POST /admin/users/{id}/sanctions", report.SanctionsHandler)
+	mux.HandleFunc("DELETE /admin/users/{id}/sanctions", report.SanctionsHandler)
+
+	mux.HandleFunc("POST /admin/users/{id}/verify", auth.VerifyHandler)
+	mux.HandleFunc("DELETE /admin/users/{id}/verify", auth.VerifyHandler)
+	mux.HandleFunc("PUT /admin/users/{id}/plan", quota.PlanHandler)
+
+	mux.HandleFunc("GET /trash", trash.ListHandler)
+	mux.HandleFunc("POST /trash/job/{id}/restore", job.UndeleteHandler)
+	mux.HandleFunc("POST /trash/ankety/{id}/restore", ankety.UndeleteHandler)
+
+	mux.HandleFunc("GET /files/{key}", storage.DownloadHandler)
 	fs := http.FileServer(http.Dir("./frontend"))
 	mux.Handle("/", fs)
 
-	// Оборачиваем роутер в CORS Middleware
-	handler := auth.CORSMiddleware(mux)
+	mux.HandleFunc("GET /ws", realtime.Handler)
+
+	// Решения модераторов применяются к вакансиям и анкетам
+	moderation.OnDecision(moderation.KindJob, job.ApplyModeration)
+	moderation.OnDecision(moderation.KindAnkety, ankety.ApplyModeration)
+	// Несколько анкет у одного пользователя не удаляются автоматически:
+	// администратор должен решить, какую оставить
+	if err := ankety.CheckDuplicates(); err != nil {
+		fmt.Printf("ВНИМАНИЕ: у пользователей несколько анкет, они не смогут откликаться до разбора:\n%v\n", err)
+	}
+	// Записи, созданные до модерации, проверяются один раз при запуске
+	if err := job.ModerateExisting(); err != nil {
+		fmt.Printf("Ошибка проверки сохраненных вакансий: %v\n", err)
+	}
+	if err := ankety.ModerateExisting(); err != nil {
+		fmt.Printf("Ошибка проверки сохраненных анкет: %v\n", err)
+	}
+	// Жалобы находят и скрывают вакансии, анкеты и сообщения через их пакеты
+	report.OnTarget(report.KindJob, report.Target{Owner: job.ReportTarget, Hide: job.SetHidden})
+	report.OnTarget(report.KindAnkety, report.Target{Owner: ankety.ReportTarget, Hide: ankety.SetHidden})
+	report.OnTarget(report.KindMessage, report.Target{Owner: messaging.ReportTarget, Hide: messaging.SetHidden})
+	// Оповещения по сохраненным поискам и доска вакансий в реальном времени
+	job.OnPublish(search.JobPublished)
+	job.OnPublish(func(j job.Job) {
+		realtime.Publish(realtime.TopicJobs, "job.published", "", j)
+	})
+	// История анкеты удаляется вместе с ней; история вакансии остается для откликов на нее
+	trash.OnPurge(trash.KindAnkety, func(it trash.Item) error {
+		return revision.Forget(revision.KindAnkety, it.ObjectID)
+	})
+	// Резюме и фото - персональные данные: при окончательном удалении анкеты файлы
+	// удаляются, если на них не ссылаются другие анкеты, вакансии, компании, сообщения и корзина
+	trash.OnPurge(trash.KindAnkety, ankety.ReleaseFiles)
+	storage.OnReferences(ankety.FileKeys)
+	storage.OnReferences(job.FileKeys)
+	storage.OnReferences(company.FileKeys)
+	storage.OnReferences(messaging.FileKeys)
+	storage.OnReferences(trash.FileKeys)
+	search.StartDigests()
+	job.StartExpiryWatcher()
+	trash.StartPurger()
+
+	// Оборачиваем роутер в CORS Middleware; каждому запросу присваивается ID,
+	// он попадает в ответы с ошибками
+	handler := problem.WithRequestID(auth.CORSMiddleware(mux))
 
 	fmt.Println("Server starting on :8080")
 	http.ListenAndServe(":8080", handler) // Используем обернутый handler
@@ -0,0 +1,143 @@
+package match
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"talant/ankety"
+	"talant/auth"
+	"talant/job"
+	"talant/problem"
+	"time"
+)
+
+const defaultLimit = 20
+
+// JobRecommendation - вакансия с оценкой для кандидата
+type JobRecommendation struct {
+	Job   job.Job `json:"job"`
+	Match Result  `json:"match"`
+}
+
+// CandidateMatch - анкета с оценкой для вакансии
+type CandidateMatch struct {
+	Ankety ankety.Ankety `json:"ankety"`
+	Match  Result        `json:"match"`
+}
+
+func limitFrom(r *http.Request) int {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		return defaultLimit
+	}
+	return limit
+}
+
+// RecommendJobsHandler подбирает вакансии для анкеты текущего пользователя (/recommendations/jobs)
+func RecommendJobsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	anketa, err := ankety.FindByUser(userID)
+	if errors.Is(err, ankety.ErrDuplicateAnkety) {
+		problem.Write(w, http.StatusConflict, problem.CodeConflict, err.Error())
+		return
+	}
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading ankety")
+		return
+	}
+	if anketa == nil {
+		problem.Write(w, http.StatusNotFound, problem.CodeAnketyNotFound, "Create an ankety to get recommendations")
+		return
+	}
+
+	jobs, err := job.LoadJobs()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading jobs")
+		return
+	}
+
+	now := time.Now()
+	recs := []JobRecommendation{}
+	for _, j := range jobs {
+		// Свои, снятые и непроверенные вакансии, а также вакансии только для взрослых несовершеннолетним не предлагаем
+		if j.UserID == userID || !j.Listed(now) || (anketa.Minor && !j.AcceptsMinors) {
+			continue
+		}
+		recs = append(recs, JobRecommendation{Job: j, Match: Score(*anketa, j)})
+	}
+	sortByScore(recs, func(rec JobRecommendation) float64 { return rec.Match.Score })
+	if limit := limitFrom(r); len(recs) > limit {
+		recs = recs[:limit]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(recs)
+}
+
+// JobMatchesHandler подбирает кандидатов на вакансию ее автору (/job/{id}/matches).
+// Учитываются только анкеты, которые автор вакансии имеет право видеть.
+func JobMatchesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	jobs, err := job.LoadJobs()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading jobs")
+		return
+	}
+	var found *job.Job
+	for i := range jobs {
+		if jobs[i].Id == r.PathValue("id") {
+			found = &jobs[i]
+			break
+		}
+	}
+	if found == nil {
+		problem.Write(w, http.StatusNotFound, problem.CodeJobNotFound, "Job not found")
+		return
+	}
+	if !found.ManagedBy(userID) {
+		problem.Write(w, http.StatusForbidden, problem.CodeForbidden, "Only the job owner can see matches")
+		return
+	}
+
+	visible, err := ankety.VisibleTo(r)
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading ankety")
+		return
+	}
+
+	matches := []CandidateMatch{}
+	for _, a := range visible {
+		if a.UserId == userID || (a.Minor && !found.AcceptsMinors) {
+			continue
+		}
+		matches = append(matches, CandidateMatch{Ankety: a, Match: Score(a, *found)})
+	}
+	sortByScore(matches, func(m CandidateMatch) float64 { return m.Match.Score })
+	if limit := limitFrom(r); len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(matches)
+}
@@ -0,0 +1,234 @@
+package match
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"talant/ankety"
+	"talant/job"
+	"unicode"
+)
+
+// Веса составляющих итоговой оценки, в сумме 1
+const (
+	weightSkills = 0.4
+	weightText   = 0.25
+	weightSalary = 0.2
+	weightSchool = 0.15
+)
+
+// skillLevelWeight - насколько уровень владения навыком засчитывается в совпадение
+var skillLevelWeight = map[string]float64{
+	"beginner":     0.5,
+	"intermediate": 0.75,
+	"advanced":     0.9,
+	"expert":       1,
+}
+
+// Component - одна составляющая оценки с пояснением
+type Component struct {
+	Name         string  `json:"name"`
+	Score        float64 `json:"score"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
+	Explanation  string  `json:"explanation"`
+}
+
+// Result - итоговая оценка пары "кандидат - вакансия"
+type Result struct {
+	Score         float64     `json:"score"`
+	Components    []Component `json:"components"`
+	MatchedSkills []string    `json:"matched_skills"`
+	MissingSkills []string    `json:"missing_skills"`
+}
+
+// Score оценивает, насколько анкета подходит к вакансии, от 0 до 1
+func Score(a ankety.Ankety, j job.Job) Result {
+	res := Result{MatchedSkills: []string{}, MissingSkills: []string{}}
+
+	skills, matched, missing := skillScore(a, j)
+	res.MatchedSkills, res.MissingSkills = matched, missing
+	res.add("skills", skills, weightSkills, skillExplanation(matched, missing))
+
+	text := cosine(terms(candidateText(a)), terms(j.Title+" "+j.Description))
+	res.add("text", text, weightText, "сходство описания вакансии с профилем кандидата")
+
+	_, offered := SalaryRange(j)
+	salary, salaryNote := salaryScore(a.SalaryExpectation, offered)
+	res.add("salary", salary, weightSalary, salaryNote)
+
+	school, schoolNote := schoolScore(a, j)
+	res.add("school", school, weightSchool, schoolNote)
+
+	res.Score = round(res.Score)
+	return res
+}
+
+func (r *Result) add(name string, score, weight float64, explanation string) {
+	r.Components = append(r.Components, Component{
+		Name:         name,
+		Score:        round(score),
+		Weight:       weight,
+		Contribution: round(score * weight),
+		Explanation:  explanation,
+	})
+	r.Score += score * weight
+}
+
+func round(f float64) float64 {
+	return math.Round(f*1000) / 1000
+}
+
+// SplitSkills разбирает строку навыков вакансии ("Go, SQL; Docker")
+func SplitSkills(s string) []string {
+	var out []string
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' || r == '\n' }) {
+		if p := strings.TrimSpace(part); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func skillScore(a ankety.Ankety, j job.Job) (float64, []string, []string) {
+	have := map[string]string{}
+	for _, s := range a.Skills {
+		have[strings.ToLower(strings.TrimSpace(s.Name))] = s.Level
+	}
+
+	required := SplitSkills(j.Skills)
+	matched, missing := []string{}, []string{}
+	if len(required) == 0 {
+		// Вакансия без требований не штрафует кандидата
+		return 0.5, matched, missing
+	}
+
+	var sum float64
+	for _, skill := range required {
+		level, ok := have[strings.ToLower(skill)]
+		if !ok {
+			missing = append(missing, skill)
+			continue
+		}
+		matched = append(matched, skill)
+		sum += skillLevelWeight[level]
+	}
+	return sum / float64(len(required)), matched, missing
+}
+
+func skillExplanation(matched, missing []string) string {
+	if len(matched)+len(missing) == 0 {
+		return "в вакансии не указаны навыки"
+	}
+	return "совпало навыков: " + strconv.Itoa(len(matched)) + " из " + strconv.Itoa(len(matched)+len(missing))
+}
+
+// SalaryRange возвращает вилку зарплаты вакансии. Если числа не указаны, обе
+// границы берутся из текста Salary; если указана одна граница - вилка из одного числа.
+func SalaryRange(j job.Job) (lo, hi int) {
+	lo, hi = j.SalaryMin, j.SalaryMax
+	if lo == 0 && hi == 0 {
+		lo = ParseSalary(j.Salary)
+		return lo, lo
+	}
+	if lo == 0 {
+		lo = hi
+	}
+	if hi == 0 {
+		hi = lo
+	}
+	return lo, hi
+}
+
+// ParseSalary достает число из строки зарплаты вакансии ("от 50 000 ₽" -> 50000)
+func ParseSalary(s string) int {
+	var digits strings.Builder
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			digits.WriteRune(r)
+		} else if digits.Len() > 0 && !unicode.IsSpace(r) {
+			break
+		}
+	}
+	n, _ := strconv.Atoi(digits.String())
+	return n
+}
+
+func salaryScore(expected, offered int) (float64, string) {
+	switch {
+	case expected == 0 || offered == 0:
+		return 0.5, "зарплата не указана"
+	case offered >= expected:
+		return 1, "зарплата не ниже ожиданий кандидата"
+	}
+	return float64(offered) / float64(expected), "зарплата ниже ожиданий кандидата"
+}
+
+func normalizeSchool(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+func schoolScore(a ankety.Ankety, j job.Job) (float64, string) {
+	want := normalizeSchool(j.School)
+	if want == "" {
+		return 0.5, "учебное заведение в вакансии не указано"
+	}
+	schools := []string{a.School}
+	for _, e := range a.Education {
+		schools = append(schools, e.School)
+	}
+	for _, s := range schools {
+		if normalizeSchool(s) == want {
+			return 1, "кандидат учится или учился в " + j.School
+		}
+	}
+	return 0, "учебное заведение не совпадает"
+}
+
+func candidateText(a ankety.Ankety) string {
+	parts := []string{a.Job}
+	for _, e := range a.Experience {
+		parts = append(parts, e.Position, e.Description)
+	}
+	for _, e := range a.Education {
+		parts = append(parts, e.Program)
+	}
+	for _, s := range a.Skills {
+		parts = append(parts, s.Name)
+	}
+	return strings.Join(parts, " ")
+}
+
+// terms разбивает текст на слова и считает их частоту. Короткие слова отбрасываются.
+func terms(text string) map[string]float64 {
+	tf := map[string]float64{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+	}) {
+		if len([]rune(w)) > 1 {
+			tf[w]++
+		}
+	}
+	return tf
+}
+
+func cosine(a, b map[string]float64) float64 {
+	var dot, na, nb float64
+	for w, x := range a {
+		dot += x * b[w]
+		na += x * x
+	}
+	for _, y := range b {
+		nb += y * y
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// sortByScore сортирует по убыванию оценки
+func sortByScore[T any](items []T, score func(T) float64) {
+	sort.SliceStable(items, func(i, k int) bool { return score(items[i]) > score(items[k]) })
+}
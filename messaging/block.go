@@ -0,0 +1,152 @@
+package messaging
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"talant/auth"
+	"talant/problem"
+	"time"
+)
+
+// Block - пользователь UserID не принимает сообщения от BlockedID
+type Block struct {
+	UserID    string    `json:"user_id"`
+	BlockedID string    `json:"blocked_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+var blocksFile string = "blocks.json"
+
+// blocksMu сериализует изменения blocks.json
+var blocksMu sync.Mutex
+
+func loadBlocks() ([]Block, error) {
+	data, err := os.ReadFile(blocksFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Block{}, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла %s: %w", blocksFile, err)
+	}
+	if len(data) == 0 {
+		return []Block{}, nil
+	}
+
+	var list []Block
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("ошибка разбора JSON из файла %s: %w", blocksFile, err)
+	}
+	return list, nil
+}
+
+func saveBlocks(list []Block) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования в JSON: %w", err)
+	}
+	if err := os.WriteFile(blocksFile, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", blocksFile, err)
+	}
+	return nil
+}
+
+// IsBlocked сообщает, заблокировал ли userID пользователя blockedID
+func IsBlocked(userID, blockedID string) (bool, error) {
+	list, err := loadBlocks()
+	if err != nil {
+		return false, err
+	}
+	for _, b := range list {
+		if b.UserID == userID && b.BlockedID == blockedID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// BlockHandler блокирует (POST) или разблокирует (DELETE) пользователя (/users/{id}/block)
+func BlockHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+	blockedID := r.PathValue("id")
+	if blockedID == userID {
+		problem.Write(w, http.StatusBadRequest, problem.CodeBadRequest, "Cannot block yourself")
+		return
+	}
+	if r.Method == http.MethodPost {
+		user, err := auth.GetUser(blockedID)
+		if err != nil {
+			problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading users")
+			return
+		}
+		if user == nil {
+			problem.Write(w, http.StatusNotFound, problem.CodeUserNotFound, "User not found")
+			return
+		}
+	}
+
+	blocksMu.Lock()
+	defer blocksMu.Unlock()
+	list, err := loadBlocks()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading blocks")
+		return
+	}
+
+	remaining := make([]Block, 0, len(list)+1)
+	for _, b := range list {
+		if b.UserID == userID && b.BlockedID == blockedID {
+			continue
+		}
+		remaining = append(remaining, b)
+	}
+	if r.Method == http.MethodPost {
+		remaining = append(remaining, Block{UserID: userID, BlockedID: blockedID, CreatedAt: time.Now().UTC()})
+	}
+	if err := saveBlocks(remaining); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving blocks")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BlocksHandler возвращает список пользователей, заблокированных текущим (GET /blocks)
+func BlocksHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	list, err := loadBlocks()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading blocks")
+		return
+	}
+	mine := []Block{}
+	for _, b := range list {
+		if b.UserID == userID {
+			mine = append(mine, b)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mine)
+}
@@ -0,0 +1,359 @@
+package messaging
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"talant/application"
+	"talant/auth"
+	"talant/notification"
+	"talant/problem"
+	"talant/realtime"
+	"talant/request"
+	"talant/storage"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Conversation - сводка переписки по отклику для списка диалогов
+type Conversation struct {
+	ApplicationID string   `json:"application_id"`
+	JobID         string   `json:"job_id"`
+	WithUserID    string   `json:"with_user_id"`
+	LastMessage   *Message `json:"last_message,omitempty"`
+	Unread        int      `json:"unread"`
+}
+
+// participant загружает отклик и проверяет, что userID - одна из его сторон.
+// Со стороны работодателя пишет автор вакансии или любой участник команды компании,
+// сообщения кандидата адресуются автору вакансии.
+// Возвращает отклик и ID собеседника либо HTTP-статус, код и текст ошибки.
+func participant(appID, userID string) (*application.Application, string, int, string, string) {
+	app, err := application.Get(appID)
+	if err != nil {
+		return nil, "", http.StatusInternalServerError, problem.CodeInternal, "Error loading applications"
+	}
+	if app == nil {
+		return nil, "", http.StatusNotFound, problem.CodeApplicationNotFound, "Application not found"
+	}
+	switch {
+	case userID == app.CandidateID:
+		return app, app.EmployerID, http.StatusOK, "", ""
+	case app.ManagedBy(userID):
+		return app, app.CandidateID, http.StatusOK, "", ""
+	}
+	// Чужой отклик неотличим от несуществующего
+	return nil, "", http.StatusNotFound, problem.CodeApplicationNotFound, "Application not found"
+}
+
+// SendInput - поля запроса сообщения; вложение передается отдельным multipart-полем file
+type SendInput struct {
+	Body string `json:"body" validate:"trim,max=5000"`
+}
+
+// SendHandler отправляет сообщение в переписку по отклику (POST /applications/{id}/messages).
+// Текст передается в поле body, вложение (PDF, JPEG, PNG) - в multipart-поле file.
+func SendHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	app, recipientID, status, code, msg := participant(r.PathValue("id"), userID)
+	if status != http.StatusOK {
+		problem.Write(w, status, code, msg)
+		return
+	}
+	// Проверяем блокировку до приема файла, чтобы не сохранять лишние загрузки
+	blocked, err := IsBlocked(recipientID, userID)
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading blocks")
+		return
+	}
+	if blocked {
+		problem.Write(w, http.StatusForbidden, problem.CodeRecipientBlocked, "Recipient has blocked you")
+		return
+	}
+
+	var attachment *storage.File
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		file, err := storage.Receive(w, r, "file", storage.AttachmentLimits)
+		switch {
+		case err == nil:
+			attachment = &file
+		case !errors.Is(err, storage.ErrMissingFile):
+			storage.UploadError(w, err)
+			return
+		}
+	}
+
+	var in SendInput
+	if _, err := request.DecodeWithFiles(w, r, &in, "file"); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if err := request.Validate(&in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	body := in.Body
+	if body == "" && attachment == nil {
+		request.WriteError(w, request.Invalid("body", "is required without a file"))
+		return
+	}
+
+	m := Message{
+		Id:            uuid.New().String(),
+		ApplicationID: app.Id,
+		SenderID:      userID,
+		RecipientID:   recipientID,
+		Body:          body,
+		Attachment:    attachment,
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	mu.Lock()
+	list, err := Load()
+	if err == nil {
+		err = Save(append(list, m))
+	}
+	mu.Unlock()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
+		return
+	}
+
+	// Отправителю событие тоже нужно: переписка может быть открыта на другом устройстве
+	realtime.Publish(realtime.TopicMessages, "message.created", recipientID, m)
+	realtime.Publish(realtime.TopicMessages, "message.created", userID, m)
+
+	preview := body
+	if preview == "" {
+		preview = "Вложение: " + attachment.Name
+	}
+	err = notification.Send(recipientID, notification.EventNewMessage,
+		"Новое сообщение", preview, "/applications/"+app.Id+"/messages")
+	if err != nil {
+		fmt.Printf("Ошибка отправки уведомления: %v\n", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(m)
+}
+
+// ListHandler возвращает переписку по отклику в хронологическом порядке
+// (GET /applications/{id}/messages). Параметр since (RFC 3339) отдает только более новые сообщения.
+func ListHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	app, _, status, code, msg := participant(r.PathValue("id"), userID)
+	if status != http.StatusOK {
+		problem.Write(w, status, code, msg)
+		return
+	}
+
+	var since time.Time
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		since, err = time.Parse(time.RFC3339, raw)
+		if err != nil {
+			problem.Write(w, http.StatusBadRequest, problem.CodeInvalidParameter, "since must be an RFC 3339 time")
+			return
+		}
+	}
+
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading messages")
+		return
+	}
+	thread := []Message{}
+	for _, m := range list {
+		if m.ApplicationID == app.Id && m.CreatedAt.After(since) {
+			thread = append(thread, m.shownTo(userID))
+		}
+	}
+	sort.SliceStable(thread, func(i, j int) bool { return thread[i].CreatedAt.Before(thread[j].CreatedAt) })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(thread)
+}
+
+// ReadHandler отмечает входящие сообщения переписки прочитанными
+// (POST /applications/{id}/messages/read) и сообщает отправителю об этом.
+func ReadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	app, senderID, status, code, msg := participant(r.PathValue("id"), userID)
+	if status != http.StatusOK {
+		problem.Write(w, status, code, msg)
+		return
+	}
+	// Входящие стороны работодателя адресованы автору вакансии
+	recipientID := userID
+	if userID != app.CandidateID {
+		recipientID = app.EmployerID
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading messages")
+		return
+	}
+
+	now := time.Now().UTC()
+	var read []string
+	for i := range list {
+		m := &list[i]
+		if m.ApplicationID == app.Id && m.RecipientID == recipientID && m.ReadAt == nil {
+			m.ReadAt = &now
+			read = append(read, m.Id)
+		}
+	}
+	if len(read) > 0 {
+		if err := Save(list); err != nil {
+			problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
+			return
+		}
+		receipt := map[string]any{"application_id": app.Id, "message_ids": read, "read_at": now}
+		realtime.Publish(realtime.TopicMessages, "message.read", senderID, receipt)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"read": len(read)})
+}
+
+// ConversationsHandler возвращает переписки текущего пользователя, начиная с последней активной (GET /conversations)
+func ConversationsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading messages")
+		return
+	}
+	apps, err := application.Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading applications")
+		return
+	}
+
+	byApp := map[string]*Conversation{}
+	var convs []*Conversation
+	for _, a := range apps {
+		with := ""
+		switch {
+		case userID == a.CandidateID:
+			with = a.EmployerID
+		case a.ManagedBy(userID):
+			with = a.CandidateID
+		default:
+			continue
+		}
+		c := &Conversation{ApplicationID: a.Id, JobID: a.JobID, WithUserID: with}
+		byApp[a.Id] = c
+		convs = append(convs, c)
+	}
+	for i := range list {
+		m := list[i].shownTo(userID)
+		c, ok := byApp[m.ApplicationID]
+		if !ok {
+			continue
+		}
+		if c.LastMessage == nil || m.CreatedAt.After(c.LastMessage.CreatedAt) {
+			c.LastMessage = &m
+		}
+		if m.RecipientID != c.WithUserID && m.ReadAt == nil {
+			c.Unread++
+		}
+	}
+
+	// Показываем только начатые переписки
+	result := []*Conversation{}
+	for _, c := range convs {
+		if c.LastMessage != nil {
+			result = append(result, c)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LastMessage.CreatedAt.After(result[j].LastMessage.CreatedAt)
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// AttachmentHandler перенаправляет участника переписки на временную ссылку
+// на вложение сообщения (GET /messages/{id}/attachment)
+func AttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading messages")
+		return
+	}
+	for _, m := range list {
+		if m.Id != r.PathValue("id") {
+			continue
+		}
+		if _, _, status, _, _ := participant(m.ApplicationID, userID); status != http.StatusOK {
+			break
+		}
+		if m.shownTo(userID).Attachment == nil {
+			problem.Write(w, http.StatusNotFound, problem.CodeFileNotFound, "File not found")
+			return
+		}
+		storage.RedirectToFile(w, r, m.Attachment.Key, m.Attachment.Name)
+		return
+	}
+	problem.Write(w, http.StatusNotFound, problem.CodeMessageNotFound, "Message not found")
+}
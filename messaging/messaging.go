@@ -0,0 +1,86 @@
+package messaging
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"talant/storage"
+	"time"
+)
+
+// Message - сообщение в переписке по отклику. Переписка ведется между
+// работодателем и кандидатом и адресуется ID отклика.
+type Message struct {
+	Id            string        `json:"id"`
+	ApplicationID string        `json:"application_id"`
+	SenderID      string        `json:"sender_id"`
+	RecipientID   string        `json:"recipient_id"`
+	Body          string        `json:"body"`
+	Attachment    *storage.File `json:"attachment,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+	// ReadAt - когда получатель прочитал сообщение (отметка о прочтении)
+	ReadAt *time.Time `json:"read_at,omitempty"`
+	// Hidden - сообщение скрыто по жалобе, получатель видит его без текста и вложения
+	Hidden bool `json:"hidden,omitempty"`
+}
+
+// shownTo возвращает сообщение в том виде, в котором его можно показать пользователю
+func (m Message) shownTo(userID string) Message {
+	if m.Hidden && m.SenderID != userID {
+		m.Body = ""
+		m.Attachment = nil
+	}
+	return m
+}
+
+// MaxBodyLength - максимальная длина текста сообщения в символах (правило max в SendInput)
+const MaxBodyLength = 5000
+
+var messagesFile string = "messages.json"
+
+// mu сериализует изменения messages.json
+var mu sync.Mutex
+
+func Load() ([]Message, error) {
+	data, err := os.ReadFile(messagesFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Message{}, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла %s: %w", messagesFile, err)
+	}
+	if len(data) == 0 {
+		return []Message{}, nil
+	}
+
+	var list []Message
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("ошибка разбора JSON из файла %s: %w", messagesFile, err)
+	}
+	return list, nil
+}
+
+func Save(list []Message) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования в JSON: %w", err)
+	}
+	if err := os.WriteFile(messagesFile, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", messagesFile, err)
+	}
+	return nil
+}
+
+// FileKeys возвращает ключи вложений всех сообщений, см. storage.OnReferences
+func FileKeys() ([]string, error) {
+	list, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, m := range list {
+		keys = append(keys, m.Attachment.Keys()...)
+	}
+	return keys, nil
+}
@@ -0,0 +1,39 @@
+package messaging
+
+import "net/http"
+
+// ReportTarget возвращает отправителя сообщения для жалобы или "", если сообщения нет
+// или жалующийся не участвует в переписке
+func ReportTarget(messageID, reporterID string) (string, error) {
+	list, err := Load()
+	if err != nil {
+		return "", err
+	}
+	for _, m := range list {
+		if m.Id != messageID {
+			continue
+		}
+		if _, _, status, _, _ := participant(m.ApplicationID, reporterID); status != http.StatusOK {
+			return "", nil
+		}
+		return m.SenderID, nil
+	}
+	return "", nil
+}
+
+// SetHidden скрывает сообщение по жалобе или возвращает его получателю
+func SetHidden(messageID string, hidden bool) error {
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		return err
+	}
+	for i := range list {
+		if list[i].Id == messageID {
+			list[i].Hidden = hidden
+			return Save(list)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package moderation
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Check - автоматическая проверка текста. Проверки подключаются через Register.
+type Check interface {
+	Name() string
+	// Run возвращает замечания к полям содержимого, пустой список - замечаний нет
+	Run(c Content) []Flag
+}
+
+var checks []Check
+
+// Register добавляет проверку в конвейер модерации
+func Register(c Check) {
+	checks = append(checks, c)
+}
+
+func init() {
+	words := DefaultWords
+	if extra, err := loadWords(wordsFile); err != nil {
+		fmt.Printf("Ошибка чтения словаря модерации: %v\n", err)
+	} else {
+		words = append(append([]string{}, words...), extra...)
+	}
+	Register(WordList{Words: words})
+	Register(Contacts{})
+	Register(Spam{})
+}
+
+// wordsFile - дополнительный словарь запрещенных слов, по одному началу слова в строке
+var wordsFile string = "moderation_words.txt"
+
+func loadWords(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var words []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if w := strings.TrimSpace(sc.Text()); w != "" && !strings.HasPrefix(w, "#") {
+			words = append(words, strings.ToLower(w))
+		}
+	}
+	return words, sc.Err()
+}
+
+// DefaultWords - начала нецензурных и оскорбительных слов. Слово считается запрещенным,
+// если оно начинается с одного из них: так ловятся словоформы без ложных срабатываний
+// на словах вроде "застрахуем".
+var DefaultWords = []string{
+	"хуй", "хуе", "хуё", "хуя", "хуи", "нахуй", "похуй",
+	"пизд", "распизд", "ебан", "ебат", "ебал", "ебл", "ёбан", "заеб", "уеб", "выеб", "проеб", "наеб", "долбоеб",
+	"бля", "сука", "суки", "мудак", "мудил", "пидор", "пидар", "залуп", "гандон", "шлюх",
+	"fuck", "shit", "bitch", "cunt",
+}
+
+// homoglyphs - латинские буквы, которыми подменяют кириллицу, чтобы обойти словарь
+var homoglyphs = strings.NewReplacer(
+	"a", "а", "b", "в", "c", "с", "e", "е", "h", "н", "k", "к", "m", "м",
+	"o", "о", "p", "р", "t", "т", "x", "х", "y", "у", "3", "з", "0", "о",
+)
+
+// words разбивает текст на слова в нижнем регистре, ё оставляется как есть
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func hasCyrillic(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// WordList отмечает поля с запрещенными словами
+type WordList struct {
+	Words []string
+}
+
+func (WordList) Name() string { return "word_list" }
+
+func (c WordList) Run(content Content) []Flag {
+	var flags []Flag
+	for _, field := range content.fieldNames() {
+		for _, w := range words(content.Fields[field]) {
+			// Смешанное написание ("xуй") приводим к кириллице
+			if hasCyrillic(w) {
+				w = homoglyphs.Replace(w)
+			}
+			if bad := c.match(w); bad != "" {
+				flags = append(flags, Flag{Check: c.Name(), Field: field, Reason: "profanity", Match: w})
+				break
+			}
+		}
+	}
+	return flags
+}
+
+func (c WordList) match(word string) string {
+	alt := strings.ReplaceAll(word, "ё", "е")
+	for _, bad := range c.Words {
+		if strings.HasPrefix(word, bad) || strings.HasPrefix(alt, bad) {
+			return bad
+		}
+	}
+	return ""
+}
+
+var (
+	urlPattern      = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+|\b[a-z0-9-]+\.(?:ru|com|su|io|me|info|biz)\b`)
+	emailPattern    = regexp.MustCompile(`(?i)[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}`)
+	phonePattern    = regexp.MustCompile(`(?:\+7|\b8)[\s(-]*\d{3}[\s)-]*\d{3}[\s-]*\d{2}[\s-]*\d{2}\b`)
+	telegramPattern = regexp.MustCompile(`(?i)(?:^|\s)@[a-z0-9_]{5,}|\bt\.me/\S+`)
+)
+
+// Contacts отмечает ссылки и контакты в тексте: они должны передаваться через
+// поля контактов и переписку, а не в описании
+type Contacts struct{}
+
+func (Contacts) Name() string { return "contacts" }
+
+func (c Contacts) Run(content Content) []Flag {
+	var flags []Flag
+	for _, field := range content.fieldNames() {
+		text := content.Fields[field]
+		for _, p := range []struct {
+			re     *regexp.Regexp
+			reason string
+		}{
+			{emailPattern, "email"},
+			{urlPattern, "link"},
+			{phonePattern, "phone"},
+			{telegramPattern, "messenger"},
+		} {
+			if m := p.re.FindString(text); m != "" {
+				flags = append(flags, Flag{Check: c.Name(), Field: field, Reason: p.reason, Match: strings.TrimSpace(m)})
+				break
+			}
+		}
+	}
+	return flags
+}
+
+// Spam отмечает признаки спама: КАПС, повторы символов и слов
+type Spam struct{}
+
+func (Spam) Name() string { return "spam" }
+
+// repeatedRun - длина серии одинаковых символов, начиная с которой текст считается спамом
+const repeatedRun = 6
+
+func (c Spam) Run(content Content) []Flag {
+	var flags []Flag
+	for _, field := range content.fieldNames() {
+		if reason := spamReason(content.Fields[field]); reason != "" {
+			flags = append(flags, Flag{Check: c.Name(), Field: field, Reason: reason})
+		}
+	}
+	return flags
+}
+
+func spamReason(text string) string {
+	letters, upper := 0, 0
+	run, prev := 0, rune(0)
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+		if r == prev && !unicode.IsSpace(r) && !unicode.IsDigit(r) {
+			run++
+			if run >= repeatedRun {
+				return "repeated_characters"
+			}
+		} else {
+			run = 1
+		}
+		prev = r
+	}
+	if letters >= 20 && float64(upper)/float64(letters) > 0.6 {
+		return "caps"
+	}
+
+	list := words(text)
+	if len(list) >= 10 {
+		count := map[string]int{}
+		for _, w := range list {
+			count[w]++
+			if count[w] >= 5 && float64(count[w])/float64(len(list)) > 0.3 {
+				return "repeated_words"
+			}
+		}
+	}
+	return ""
+}
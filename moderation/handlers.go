@@ -0,0 +1,153 @@
+package moderation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"talant/auth"
+	"talant/notification"
+	"talant/problem"
+	"talant/request"
+	"time"
+)
+
+var kindNames = map[string]string{KindJob: "Вакансия", KindAnkety: "Анкета"}
+
+var kindLinks = map[string]string{KindJob: "/job/", KindAnkety: "/ankety/"}
+
+// DecisionInput - поля запроса решения модератора
+type DecisionInput struct {
+	Reason string `json:"reason" validate:"trim,max=1000"`
+}
+
+// QueueHandler возвращает очередь модерации (GET /moderation/queue).
+// Параметры: state (по умолчанию pending), kind. Доступно модераторам.
+func QueueHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+	if !auth.IsModerator(userID) {
+		problem.Write(w, http.StatusForbidden, problem.CodeModeratorsOnly, "Moderators only")
+		return
+	}
+
+	state := r.URL.Query().Get("state")
+	if state == "" {
+		state = StatePending
+	}
+	kind := r.URL.Query().Get("kind")
+
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading moderation queue")
+		return
+	}
+	result := []Item{}
+	for _, it := range list {
+		if it.State == state && (kind == "" || it.Kind == kind) {
+			result = append(result, it)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// DecideHandler принимает решение по элементу очереди
+// (POST /moderation/queue/{id}/approve или /reject с обязательным полем reason).
+// Решение сохраняется в содержимом, автор получает уведомление с причиной.
+func DecideHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+	if !auth.IsModerator(userID) {
+		problem.Write(w, http.StatusForbidden, problem.CodeModeratorsOnly, "Moderators only")
+		return
+	}
+
+	var state string
+	switch r.PathValue("decision") {
+	case "approve":
+		state = StateApproved
+	case "reject":
+		state = StateRejected
+	default:
+		problem.Write(w, http.StatusNotFound, problem.CodeNotFound, "Unknown decision")
+		return
+	}
+	var in DecisionInput
+	if _, err := request.Decode(w, r, &in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if err := request.Validate(&in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	reason := in.Reason
+	if state == StateRejected && reason == "" {
+		request.WriteError(w, request.Invalid("reason", "is required to reject"))
+		return
+	}
+
+	now := time.Now().UTC()
+	it, err := decide(r.PathValue("id"), userID, state, reason, now)
+	switch {
+	case errors.Is(err, ErrItemNotFound):
+		problem.Write(w, http.StatusNotFound, problem.CodeItemNotFound, "Item not found")
+		return
+	case errors.Is(err, ErrReviewed):
+		problem.Write(w, http.StatusConflict, problem.CodeAlreadyReviewed, "Item is already reviewed")
+		return
+	case err != nil:
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
+		return
+	}
+
+	// Решение сохраняется в содержимом уже без блокировки очереди: обработчики
+	// вакансий и анкет вызывают Submit под своей блокировкой, и обратный порядок
+	// захвата привел бы к взаимной блокировке
+	hook, ok := decisionHooks[it.Kind]
+	if !ok {
+		reopen(it.Id)
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Unknown content kind")
+		return
+	}
+	status := Status{State: state, Reason: reason, ReviewedAt: &now}
+	if state == StateRejected {
+		status.Flags = it.Flags
+	}
+	if err := hook(it.ContentID, status); err != nil {
+		fmt.Printf("Ошибка сохранения решения модерации: %v\n", err)
+		reopen(it.Id)
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving decision")
+		return
+	}
+
+	title, body := kindNames[it.Kind]+" «"+it.Title+"» опубликована", reason
+	if state == StateRejected {
+		title = kindNames[it.Kind] + " «" + it.Title + "» отклонена модератором"
+		body = "Причина: " + reason
+	}
+	if err := notification.Send(it.AuthorID, notification.EventModeration, title, body, kindLinks[it.Kind]+it.ContentID); err != nil {
+		fmt.Printf("Ошибка отправки уведомления: %v\n", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(it)
+}
@@ -0,0 +1,261 @@
+package moderation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Состояния модерации содержимого
+const (
+	StatePending  = "pending"  // автоматические проверки нашли замечания, ждет модератора
+	StateApproved = "approved" // проверки пройдены или модератор одобрил
+	StateRejected = "rejected" // модератор отклонил, причина видна автору
+)
+
+// Виды содержимого, которое проходит модерацию
+const (
+	KindJob    = "job"
+	KindAnkety = "ankety"
+)
+
+// Flag - замечание автоматической проверки к полю
+type Flag struct {
+	Check  string `json:"check"`
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+	Match  string `json:"match,omitempty"`
+}
+
+// Status - состояние модерации, которое хранится в самой вакансии или анкете.
+// Пустое состояние - у записей, созданных до модерации: они не показываются,
+// пока не пройдут проверку (см. ModerateExisting в пакетах job и ankety).
+type Status struct {
+	State      string     `json:"state"`
+	Flags      []Flag     `json:"flags,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	// Hidden - содержимое скрыто по жалобам пользователей до решения администратора.
+	// Повторная проверка и решения модератора этот признак не снимают.
+	Hidden bool `json:"hidden,omitempty"`
+}
+
+// Visible сообщает, можно ли показывать содержимое другим пользователям
+func (s Status) Visible() bool {
+	return s.State == StateApproved && !s.Hidden
+}
+
+// Content - текст вакансии или анкеты для проверки
+type Content struct {
+	Kind     string
+	ID       string
+	AuthorID string
+	Title    string
+	// Fields - проверяемые поля: имя поля -> текст
+	Fields map[string]string
+}
+
+// fieldNames возвращает имена полей в постоянном порядке, чтобы замечания не перемешивались
+func (c Content) fieldNames() []string {
+	names := make([]string, 0, len(c.Fields))
+	for name := range c.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Item - элемент очереди модерации
+type Item struct {
+	Id        string            `json:"id"`
+	Kind      string            `json:"kind"`
+	ContentID string            `json:"content_id"`
+	AuthorID  string            `json:"author_id"`
+	Title     string            `json:"title"`
+	Fields    map[string]string `json:"fields"`
+	Flags     []Flag            `json:"flags"`
+	State     string            `json:"state"`
+	Reason    string            `json:"reason,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	// ReviewedBy и ReviewedAt заполняются решением модератора
+	ReviewedBy string     `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+}
+
+var queueFile string = "moderation_queue.json"
+
+// mu сериализует изменения moderation_queue.json
+var mu sync.Mutex
+
+func Load() ([]Item, error) {
+	data, err := os.ReadFile(queueFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Item{}, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла %s: %w", queueFile, err)
+	}
+	if len(data) == 0 {
+		return []Item{}, nil
+	}
+
+	var list []Item
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("ошибка разбора JSON из файла %s: %w", queueFile, err)
+	}
+	return list, nil
+}
+
+func Save(list []Item) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования в JSON: %w", err)
+	}
+	if err := os.WriteFile(queueFile, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", queueFile, err)
+	}
+	return nil
+}
+
+// Evaluate прогоняет содержимое через все зарегистрированные проверки
+func Evaluate(c Content) []Flag {
+	flags := []Flag{}
+	for _, check := range checks {
+		flags = append(flags, check.Run(c)...)
+	}
+	return flags
+}
+
+// Submit проверяет новое или измененное содержимое. Без замечаний оно сразу одобряется,
+// иначе попадает в очередь модерации. Предыдущий неразобранный элемент для того же
+// содержимого заменяется, чтобы модератор видел только последнюю версию.
+func Submit(c Content) (Status, error) {
+	flags := Evaluate(c)
+
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		return Status{}, err
+	}
+	queue := list[:0]
+	for _, it := range list {
+		if it.State == StatePending && it.Kind == c.Kind && it.ContentID == c.ID {
+			continue
+		}
+		queue = append(queue, it)
+	}
+
+	status := Status{State: StateApproved}
+	if len(flags) > 0 {
+		status = Status{State: StatePending, Flags: flags}
+		queue = append(queue, Item{
+			Id:        uuid.New().String(),
+			Kind:      c.Kind,
+			ContentID: c.ID,
+			AuthorID:  c.AuthorID,
+			Title:     c.Title,
+			Fields:    c.Fields,
+			Flags:     flags,
+			State:     StatePending,
+			CreatedAt: time.Now().UTC(),
+		})
+	}
+	if err := Save(queue); err != nil {
+		return Status{}, err
+	}
+	return status, nil
+}
+
+// Withdraw убирает из очереди неразобранные элементы содержимого, которое так и
+// не было сохранено, например при откате импорта
+func Withdraw(kind, contentID string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		return err
+	}
+	queue := list[:0]
+	for _, it := range list {
+		if it.State == StatePending && it.Kind == kind && it.ContentID == contentID {
+			continue
+		}
+		queue = append(queue, it)
+	}
+	if len(queue) == len(list) {
+		return nil
+	}
+	return Save(queue)
+}
+
+// decisionHooks - по виду содержимого: функции, которые сохраняют решение модератора
+// в самой вакансии или анкете
+var decisionHooks = map[string]func(contentID string, s Status) error{}
+
+// OnDecision регистрирует сохранение решения для вида содержимого.
+// Функция должна вернуть nil, если содержимое уже удалено.
+func OnDecision(kind string, fn func(contentID string, s Status) error) {
+	decisionHooks[kind] = fn
+}
+
+var (
+	ErrItemNotFound = errors.New("moderation item not found")
+	ErrReviewed     = errors.New("moderation item is already reviewed")
+)
+
+// decide записывает решение модератора в элемент очереди и возвращает его.
+// В содержимое решение переносит вызывающий, уже после освобождения mu.
+func decide(itemID, moderatorID, state, reason string, now time.Time) (Item, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		return Item{}, err
+	}
+	for i := range list {
+		if list[i].Id != itemID {
+			continue
+		}
+		if list[i].State != StatePending {
+			return Item{}, ErrReviewed
+		}
+		list[i].State = state
+		list[i].Reason = reason
+		list[i].ReviewedBy = moderatorID
+		list[i].ReviewedAt = &now
+		if err := Save(list); err != nil {
+			return Item{}, err
+		}
+		return list[i], nil
+	}
+	return Item{}, ErrItemNotFound
+}
+
+// reopen возвращает элемент в очередь, если решение не удалось сохранить в содержимом
+func reopen(itemID string) {
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		fmt.Printf("Ошибка возврата элемента модерации %s в очередь: %v\n", itemID, err)
+		return
+	}
+	for i := range list {
+		if list[i].Id == itemID {
+			list[i].State = StatePending
+			list[i].Reason = ""
+			list[i].ReviewedBy = ""
+			list[i].ReviewedAt = nil
+		}
+	}
+	if err := Save(list); err != nil {
+		fmt.Printf("Ошибка возврата элемента модерации %s в очередь: %v\n", itemID, err)
+	}
+}
@@ -0,0 +1,201 @@
+package notification
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/smtp"
+	"strings"
+	"syscall"
+	"talant/auth"
+	"talant/realtime"
+	"time"
+)
+
+// Каналы доставки
+const (
+	ChannelInApp   = "in_app"
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+)
+
+// Channel - способ доставки уведомления пользователю
+type Channel interface {
+	Name() string
+	Deliver(n Notification, prefs Preferences) error
+}
+
+// channels - зарегистрированные каналы по имени
+var channels = map[string]Channel{
+	ChannelInApp:   inAppChannel{},
+	ChannelEmail:   &EmailChannel{},
+	ChannelWebhook: &WebhookChannel{Client: newWebhookClient()},
+}
+
+// Register добавляет или заменяет канал доставки
+func Register(ch Channel) {
+	channels[ch.Name()] = ch
+}
+
+// inAppChannel сохраняет уведомление в ленту /notifications
+type inAppChannel struct{}
+
+func (inAppChannel) Name() string { return ChannelInApp }
+
+func (inAppChannel) Deliver(n Notification, _ Preferences) error {
+	if err := store(n); err != nil {
+		return err
+	}
+	realtime.Publish(realtime.TopicNotifications, "notification.created", n.UserID, n)
+	return nil
+}
+
+// EmailChannel отправляет письмо на адрес из профиля пользователя.
+// Если Addr не задан, письмо только пишется в лог.
+type EmailChannel struct {
+	Addr string // host:port SMTP-сервера
+	From string
+	Auth smtp.Auth
+}
+
+func (c *EmailChannel) Name() string { return ChannelEmail }
+
+func (c *EmailChannel) Deliver(n Notification, _ Preferences) error {
+	user, err := auth.GetUser(n.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.Usermail == "" {
+		return fmt.Errorf("у пользователя %s нет email", n.UserID)
+	}
+	if c.Addr == "" {
+		fmt.Printf("Письмо для %s не отправлено (SMTP не настроен): %s\n", user.Usermail, n.Title)
+		return nil
+	}
+
+	// Заголовки не должны содержать переводов строк: заголовок уведомления
+	// может включать текст пользователя, например название сохраненного поиска
+	msg := strings.Join([]string{
+		"From: " + headerValue(c.From),
+		"To: " + headerValue(user.Usermail),
+		"Subject: " + mime.QEncoding.Encode("utf-8", headerValue(n.Title)),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		n.Body,
+		n.Link,
+	}, "\r\n")
+	return smtp.SendMail(c.Addr, c.Auth, c.From, []string{user.Usermail}, []byte(msg))
+}
+
+// headerValue убирает из значения заголовка письма CR и LF
+func headerValue(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// WebhookChannel отправляет уведомление POST-запросом на адрес из настроек пользователя.
+// Тело подписывается HMAC-SHA256 секретом пользователя в заголовке X-Talant-Signature.
+type WebhookChannel struct {
+	Client *http.Client
+}
+
+func (c *WebhookChannel) Name() string { return ChannelWebhook }
+
+func (c *WebhookChannel) Deliver(n Notification, prefs Preferences) error {
+	if prefs.WebhookURL == "" {
+		return fmt.Errorf("webhook не настроен")
+	}
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, prefs.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("webhook: схема %q не поддерживается", req.URL.Scheme)
+	}
+	mac := hmac.New(sha256.New, []byte(prefs.WebhookSecret))
+	mac.Write(body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Talant-Event", n.Event)
+	req.Header.Set("X-Talant-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook ответил %s", resp.Status)
+	}
+	return nil
+}
+
+// ErrPrivateAddress - webhook указывает на непубличный адрес: loopback, частную сеть,
+// link-local и т.п. Такие запросы сервера к своей сети не выполняются.
+var ErrPrivateAddress = errors.New("webhook: адрес не публичный")
+
+// reservedPrefixes - диапазоны, которые netip не относит к частным, но которые
+// не ведут в интернет
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// newWebhookClient возвращает клиента, который соединяется только с публичными
+// адресами. Адрес проверяется в Control уже после разрешения DNS, при каждом
+// соединении, поэтому ни имя с внутренним адресом, ни редирект туда не проходят.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: publicOnly}
+	return &http.Client{
+		Timeout: 5 * time.Second,
+		// Без Proxy: через прокси проверялся бы адрес прокси, а не получателя
+		Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: 5 * time.Second},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("webhook: редирект на схему %q", req.URL.Scheme)
+			}
+			if len(via) >= 5 {
+				return errors.New("webhook: слишком много редиректов")
+			}
+			return nil
+		},
+	}
+}
+
+// publicOnly - net.Dialer.Control: запрещает соединения с непубличными адресами
+func publicOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return ErrPrivateAddress
+	}
+	for _, p := range reservedPrefixes {
+		if p.Contains(ip) {
+			return ErrPrivateAddress
+		}
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package notification
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"talant/auth"
+	"talant/problem"
+	"talant/request"
+	"time"
+)
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+// Page - страница ленты уведомлений
+type Page struct {
+	Items   []Notification `json:"items"`
+	Page    int            `json:"page"`
+	PerPage int            `json:"per_page"`
+	Total   int            `json:"total"`
+	Unread  int            `json:"unread"`
+}
+
+// ListHandler возвращает уведомления текущего пользователя, новые сверху
+// (GET /notifications?page=1&per_page=20&unread=true)
+func ListHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	q := r.URL.Query()
+	page, err := strconv.Atoi(q.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	perPage, err := strconv.Atoi(q.Get("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = defaultPerPage
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+	onlyUnread := q.Get("unread") == "true"
+
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading notifications")
+		return
+	}
+
+	result := Page{Items: []Notification{}, Page: page, PerPage: perPage}
+	var mine []Notification
+	for _, n := range list {
+		if n.UserID != userID {
+			continue
+		}
+		if n.ReadAt == nil {
+			result.Unread++
+		} else if onlyUnread {
+			continue
+		}
+		mine = append(mine, n)
+	}
+	sort.SliceStable(mine, func(i, k int) bool { return mine[i].CreatedAt.After(mine[k].CreatedAt) })
+
+	result.Total = len(mine)
+	if start := (page - 1) * perPage; start < len(mine) {
+		end := min(start+perPage, len(mine))
+		result.Items = mine[start:end]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// ReadHandler отмечает уведомления прочитанными:
+// POST /notifications/{id}/read - одно, POST /notifications/read-all - все
+func ReadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+	id := r.PathValue("id")
+
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading notifications")
+		return
+	}
+
+	now := time.Now().UTC()
+	found := false
+	for i := range list {
+		if list[i].UserID != userID || (id != "" && list[i].Id != id) {
+			continue
+		}
+		found = true
+		if list[i].ReadAt == nil {
+			list[i].ReadAt = &now
+		}
+	}
+	if id != "" && !found {
+		problem.Write(w, http.StatusNotFound, problem.CodeNotFound, "Notification not found")
+		return
+	}
+	if err := Save(list); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving notifications")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PreferencesInput - настройки уведомлений в PUT: для каждого события из Events
+// список каналов через запятую
+type PreferencesInput struct {
+	NewApplicant string `json:"new_applicant"`
+	StatusChange string `json:"status_change"`
+	JobExpiring  string `json:"job_expiring"`
+	NewMessage   string `json:"new_message"`
+	JobAlert     string `json:"job_alert"`
+	Interview    string `json:"interview"`
+	Moderation   string `json:"moderation"`
+	Report       string `json:"report"`
+	WebhookURL   string `json:"webhook_url" validate:"trim,url"`
+}
+
+// events возвращает каналы по событиям; новое событие добавляется и сюда, и в Events
+func (in PreferencesInput) events() map[string]string {
+	return map[string]string{
+		EventNewApplicant: in.NewApplicant,
+		EventStatusChange: in.StatusChange,
+		EventJobExpiring:  in.JobExpiring,
+		EventNewMessage:   in.NewMessage,
+		EventJobAlert:     in.JobAlert,
+		EventInterview:    in.Interview,
+		EventModeration:   in.Moderation,
+		EventReport:       in.Report,
+	}
+}
+
+// PreferencesHandler показывает (GET) и меняет (PUT) настройки уведомлений.
+// В PUT для каждого события передается список каналов через запятую,
+// например new_applicant=in_app,email; пустое значение отключает событие.
+func PreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPut {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	prefs, err := PreferencesOf(userID)
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading preferences")
+		return
+	}
+
+	if r.Method == http.MethodPut {
+		var in PreferencesInput
+		sent, err := request.Decode(w, r, &in)
+		if err != nil {
+			request.WriteError(w, err)
+			return
+		}
+		if err := request.Validate(&in); err != nil {
+			request.WriteError(w, err)
+			return
+		}
+		values := in.events()
+		for _, event := range Events {
+			if !sent.Has(event) {
+				continue
+			}
+			chans := []string{}
+			for _, name := range strings.Split(values[event], ",") {
+				name = strings.TrimSpace(name)
+				if name == "" {
+					continue
+				}
+				if _, ok := channels[name]; !ok {
+					request.WriteError(w, request.Invalid(event, "unknown channel "+name))
+					return
+				}
+				if !slices.Contains(chans, name) {
+					chans = append(chans, name)
+				}
+			}
+			prefs.Events[event] = chans
+		}
+
+		if sent.Has("webhook_url") {
+			raw := in.WebhookURL
+			if raw != prefs.WebhookURL {
+				prefs.WebhookSecret = ""
+				if raw != "" {
+					prefs.WebhookSecret = newWebhookSecret()
+				}
+			}
+			prefs.WebhookURL = raw
+		}
+
+		if err := SetPreferences(prefs); err != nil {
+			problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving preferences")
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prefs)
+}
@@ -0,0 +1,123 @@
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы событий, о которых уведомляется пользователь
+const (
+	EventNewApplicant = "new_applicant"
+	EventStatusChange = "status_change"
+	EventJobExpiring  = "job_expiring"
+	EventNewMessage   = "new_message"
+	EventJobAlert     = "job_alert"
+	EventInterview    = "interview"
+	EventModeration   = "moderation"
+	EventReport       = "report"
+)
+
+// Events - все типы событий, для которых можно настроить каналы доставки
+var Events = []string{EventNewApplicant, EventStatusChange, EventJobExpiring, EventNewMessage, EventJobAlert, EventInterview, EventModeration, EventReport}
+
+// Notification - уведомление пользователя
+type Notification struct {
+	Id        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	Event     string     `json:"event"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	Link      string     `json:"link,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+}
+
+var notificationsFile string = "notifications.json"
+
+// mu сериализует изменения notifications.json
+var mu sync.Mutex
+
+func Load() ([]Notification, error) {
+	data, err := os.ReadFile(notificationsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Notification{}, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла %s: %w", notificationsFile, err)
+	}
+	if len(data) == 0 {
+		return []Notification{}, nil
+	}
+
+	var list []Notification
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("ошибка разбора JSON из файла %s: %w", notificationsFile, err)
+	}
+	return list, nil
+}
+
+func Save(list []Notification) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования в JSON: %w", err)
+	}
+	if err := os.WriteFile(notificationsFile, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", notificationsFile, err)
+	}
+	return nil
+}
+
+// store сохраняет уведомление в ленту пользователя
+func store(n Notification) error {
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		return err
+	}
+	return Save(append(list, n))
+}
+
+// Send доставляет уведомление по каналам, которые пользователь включил для этого события.
+// Ошибка возвращается только для in-app канала, внешние каналы работают в фоне.
+func Send(userID, event, title, body, link string) error {
+	n := Notification{
+		Id:        uuid.New().String(),
+		UserID:    userID,
+		Event:     event,
+		Title:     title,
+		Body:      body,
+		Link:      link,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	prefs, err := PreferencesOf(userID)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, name := range prefs.Events[event] {
+		ch, ok := channels[name]
+		if !ok {
+			continue
+		}
+		if name == ChannelInApp {
+			if err := ch.Deliver(n, prefs); err != nil && firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		go func(ch Channel) {
+			if err := ch.Deliver(n, prefs); err != nil {
+				fmt.Printf("Ошибка доставки уведомления через %s: %v\n", ch.Name(), err)
+			}
+		}(ch)
+	}
+	return firstErr
+}
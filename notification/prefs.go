@@ -0,0 +1,115 @@
+package notification
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Preferences - настройки уведомлений пользователя: какие каналы включены для каждого события
+type Preferences struct {
+	UserID        string              `json:"user_id"`
+	Events        map[string][]string `json:"events"`
+	WebhookURL    string              `json:"webhook_url,omitempty"`
+	WebhookSecret string              `json:"webhook_secret,omitempty"`
+}
+
+var prefsFile string = "notification_prefs.json"
+
+// prefsMu сериализует изменения notification_prefs.json
+var prefsMu sync.Mutex
+
+// DefaultPreferences - настройки для пользователя, который их не менял
+func DefaultPreferences(userID string) Preferences {
+	return Preferences{
+		UserID: userID,
+		Events: map[string][]string{
+			EventNewApplicant: {ChannelInApp, ChannelEmail},
+			EventStatusChange: {ChannelInApp, ChannelEmail},
+			EventJobExpiring:  {ChannelInApp, ChannelEmail},
+			EventNewMessage:   {ChannelInApp},
+			EventJobAlert:     {ChannelInApp},
+			EventInterview:    {ChannelInApp, ChannelEmail},
+			EventModeration:   {ChannelInApp, ChannelEmail},
+			EventReport:       {ChannelInApp, ChannelEmail},
+		},
+	}
+}
+
+func loadPrefs() ([]Preferences, error) {
+	data, err := os.ReadFile(prefsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Preferences{}, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла %s: %w", prefsFile, err)
+	}
+	if len(data) == 0 {
+		return []Preferences{}, nil
+	}
+
+	var list []Preferences
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("ошибка разбора JSON из файла %s: %w", prefsFile, err)
+	}
+	return list, nil
+}
+
+func savePrefs(list []Preferences) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования в JSON: %w", err)
+	}
+	if err := os.WriteFile(prefsFile, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", prefsFile, err)
+	}
+	return nil
+}
+
+// PreferencesOf возвращает настройки пользователя; для событий, которых нет
+// в сохраненных настройках, берутся значения по умолчанию
+func PreferencesOf(userID string) (Preferences, error) {
+	list, err := loadPrefs()
+	if err != nil {
+		return Preferences{}, err
+	}
+	prefs := DefaultPreferences(userID)
+	for _, p := range list {
+		if p.UserID == userID {
+			for event, chans := range p.Events {
+				prefs.Events[event] = chans
+			}
+			prefs.WebhookURL = p.WebhookURL
+			prefs.WebhookSecret = p.WebhookSecret
+			break
+		}
+	}
+	return prefs, nil
+}
+
+// SetPreferences сохраняет настройки пользователя
+func SetPreferences(p Preferences) error {
+	prefsMu.Lock()
+	defer prefsMu.Unlock()
+
+	list, err := loadPrefs()
+	if err != nil {
+		return err
+	}
+	for i := range list {
+		if list[i].UserID == p.UserID {
+			list[i] = p
+			return savePrefs(list)
+		}
+	}
+	return savePrefs(append(list, p))
+}
+
+func newWebhookSecret() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
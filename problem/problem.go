@@ -0,0 +1,130 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Коды ошибок. Код не меняется вместе с текстом detail, поэтому клиенты
+// ветвятся по нему, а не по тексту.
+const (
+	CodeBadRequest           = "bad_request"
+	CodeValidation           = "validation_failed"
+	CodeMalformedBody        = "malformed_body"
+	CodeBodyTooLarge         = "body_too_large"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeMissingID            = "missing_id"
+	CodeInvalidParameter     = "invalid_parameter"
+
+	CodeUnauthorized       = "unauthorized"
+	CodeInvalidCredentials = "invalid_credentials"
+	CodeAccountBanned      = "account_banned"
+	CodeAccountSuspended   = "account_suspended"
+
+	CodeForbidden               = "forbidden"
+	CodeAdminOnly               = "admin_only"
+	CodeModeratorsOnly          = "moderators_only"
+	CodeRecipientBlocked        = "recipient_blocked"
+	CodeAdultsOnly              = "adults_only"
+	CodeGuardianConsentRequired = "guardian_consent_required"
+
+	CodeNotFound            = "not_found"
+	CodeJobNotFound         = "job_not_found"
+	CodeAnketyNotFound      = "ankety_not_found"
+	CodeRevisionNotFound    = "revision_not_found"
+	CodeApplicationNotFound = "application_not_found"
+	CodeUserNotFound        = "user_not_found"
+	CodeSchoolNotFound      = "school_not_found"
+	CodeFileNotFound        = "file_not_found"
+	CodeCompanyNotFound     = "company_not_found"
+	CodeInterviewNotFound   = "interview_not_found"
+	CodeSearchNotFound      = "search_not_found"
+	CodeCaseNotFound        = "case_not_found"
+	CodeMessageNotFound     = "message_not_found"
+	CodeItemNotFound        = "moderation_item_not_found"
+
+	CodeMethodNotAllowed = "method_not_allowed"
+
+	CodeConflict           = "conflict"
+	CodeAlreadyExists      = "already_exists"
+	CodeAlreadyApplied     = "already_applied"
+	CodeAlreadyReported    = "already_reported"
+	CodeAlreadyReviewed    = "already_reviewed"
+	CodeDuplicateJob       = "duplicate_job"
+	CodeRestoreConflict    = "restore_conflict"
+	CodePreconditionFailed = "precondition_failed"
+	CodeRestoreExpired     = "restore_expired"
+
+	CodeAnketyRequired   = "ankety_required"
+	CodeOwnJob           = "own_job"
+	CodeConsentNotNeeded = "consent_not_needed"
+	CodeQuotaExceeded    = "quota_exceeded"
+	CodeFileTooLarge     = "file_too_large"
+	CodeUnsupportedFile  = "unsupported_file_type"
+	CodeMissingFile      = "missing_file"
+
+	CodeInternal = "internal_error"
+)
+
+// FieldError - ошибка в одном поле запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Problem - описание ошибки в формате RFC 7807 (application/problem+json)
+// с кодом ошибки, ID запроса и ошибками по полям
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// Write отвечает ошибкой в формате problem+json; замена http.Error
+func Write(w http.ResponseWriter, status int, code, detail string) {
+	WriteFields(w, status, code, detail, nil)
+}
+
+// WriteFields отвечает ошибкой с перечнем ошибок по полям запроса
+func WriteFields(w http.ResponseWriter, status int, code, detail string, fields []FieldError) {
+	p := Problem{
+		Type:      "/problems/" + code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Code:      code,
+		RequestID: w.Header().Get(RequestIDHeader),
+		Errors:    fields,
+	}
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "application/problem+json")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// CodeFor - общий код для статуса, когда обработчик получает статус от
+// вспомогательной функции и не знает точной причины
+func CodeFor(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	default:
+		return CodeInternal
+	}
+}
@@ -0,0 +1,41 @@
+package problem
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader - заголовок с ID запроса. Его же содержит поле request_id
+// ответа с ошибкой, чтобы запрос можно было найти в логах.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestID - ID клиента длиннее этого заменяется своим
+const maxRequestID = 64
+
+// WithRequestID присваивает запросу ID и отдает его в заголовке ответа.
+// ID, переданный клиентом или прокси, сохраняется, если он короткий и из безопасных символов.
+func WithRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.New().String()
+			r.Header.Set(RequestIDHeader, id)
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestID {
+		return false
+	}
+	for _, c := range id {
+		ok := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.'
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
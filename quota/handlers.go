@@ -0,0 +1,67 @@
+package quota
+
+import (
+	"errors"
+	"net/http"
+	"talant/auth"
+	"talant/problem"
+	"talant/request"
+)
+
+// PlanInput - поля запроса тарифного плана
+type PlanInput struct {
+	Plan string `json:"plan" validate:"trim"`
+}
+
+// PlanHandler назначает пользователю тарифный план (PUT /admin/users/{id}/plan, поле plan).
+// Пустой plan возвращает план по умолчанию для роли. Доступно только администраторам.
+func PlanHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	adminID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+	if auth.RoleOf(adminID) != auth.RoleAdmin {
+		problem.Write(w, http.StatusForbidden, problem.CodeAdminOnly, "Admin only")
+		return
+	}
+
+	var in PlanInput
+	if _, err := request.Decode(w, r, &in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if err := request.Validate(&in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	plan := in.Plan
+	cfg, err := LoadConfig()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading quotas")
+		return
+	}
+	if _, ok := cfg.Plans[plan]; plan != "" && !ok {
+		request.WriteError(w, request.Invalid("plan", "is not a known plan"))
+		return
+	}
+
+	err = auth.UpdateUser(r.PathValue("id"), func(u *auth.User) {
+		u.Plan = plan
+	})
+	if errors.Is(err, auth.ErrUserNotFound) {
+		problem.Write(w, http.StatusNotFound, problem.CodeUserNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
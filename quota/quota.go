@@ -0,0 +1,307 @@
+package quota
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"talant/auth"
+	"talant/problem"
+	"time"
+)
+
+// Unlimited - значение лимита без ограничения
+const Unlimited = -1
+
+// Plan - лимиты публикации вакансий
+type Plan struct {
+	// MaxActive - сколько вакансий пользователя могут быть опубликованы одновременно
+	MaxActive int `json:"max_active"`
+	// MaxPerDay - сколько вакансий можно создать за последние 24 часа
+	MaxPerDay int `json:"max_per_day"`
+	// FeaturedSlots - сколько активных вакансий можно выделить на доске
+	FeaturedSlots int `json:"featured_slots"`
+}
+
+// Config - тарифные планы и план по умолчанию для каждой роли
+type Config struct {
+	Plans     map[string]Plan   `json:"plans"`
+	RolePlans map[string]string `json:"role_plans"`
+	// DefaultPlan - план для ролей, которых нет в RolePlans
+	DefaultPlan string `json:"default_plan,omitempty"`
+}
+
+// DefaultConfig используется, если файл quotas.json отсутствует.
+// План basic повторяет прежнее правило "одна вакансия на пользователя".
+var DefaultConfig = Config{
+	Plans: map[string]Plan{
+		"basic":     {MaxActive: 1, MaxPerDay: 1, FeaturedSlots: 0},
+		"free":      {MaxActive: 5, MaxPerDay: 5, FeaturedSlots: 0},
+		"pro":       {MaxActive: 50, MaxPerDay: 20, FeaturedSlots: 5},
+		"unlimited": {MaxActive: Unlimited, MaxPerDay: Unlimited, FeaturedSlots: Unlimited},
+	},
+	RolePlans: map[string]string{
+		auth.RoleCandidate: "basic",
+		auth.RoleEmployer:  "free",
+		auth.RoleModerator: "free",
+		auth.RoleAdmin:     "unlimited",
+	},
+	DefaultPlan: "basic",
+}
+
+var configFile string = "quotas.json"
+
+// LoadConfig читает планы из quotas.json, при его отсутствии возвращает DefaultConfig
+func LoadConfig() (Config, error) {
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return DefaultConfig, nil
+		}
+		return Config{}, fmt.Errorf("ошибка чтения файла %s: %w", configFile, err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("ошибка разбора JSON из файла %s: %w", configFile, err)
+	}
+	return cfg, nil
+}
+
+// PlanOf возвращает имя и лимиты плана пользователя: назначенный план или план его роли
+func PlanOf(userID string) (string, Plan, error) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return "", Plan{}, err
+	}
+	user, err := auth.GetUser(userID)
+	if err != nil {
+		return "", Plan{}, err
+	}
+	name := ""
+	if user != nil {
+		name = user.Plan
+	}
+	if _, ok := cfg.Plans[name]; !ok {
+		name = cfg.RolePlans[auth.RoleOf(userID)]
+	}
+	if _, ok := cfg.Plans[name]; !ok && cfg.DefaultPlan != "" {
+		name = cfg.DefaultPlan
+	}
+	plan, ok := cfg.Plans[name]
+	if !ok {
+		return "", Plan{}, fmt.Errorf("plan %q is not configured", name)
+	}
+	return name, plan, nil
+}
+
+// Usage - текущее использование квоты. Считается владельцем данных (пакетом job)
+// под той же блокировкой, под которой сохраняется результат операции.
+type Usage struct {
+	Active   int `json:"active"`
+	Featured int `json:"featured"`
+}
+
+// Request - что добавляет операция к текущему использованию
+type Request struct {
+	Create   bool // новая вакансия, учитывается в дневном лимите
+	Activate bool // вакансия становится активной
+	Feature  bool // вакансия занимает выделенный слот
+}
+
+// LimitError - операция превышает лимит плана
+type LimitError struct {
+	Kind  string // active, daily, featured
+	Limit int
+}
+
+func (e *LimitError) Error() string {
+	switch e.Kind {
+	case "daily":
+		return fmt.Sprintf("daily limit of %d new jobs reached", e.Limit)
+	case "featured":
+		return fmt.Sprintf("all %d featured slots are in use", e.Limit)
+	}
+	return fmt.Sprintf("limit of %d active jobs reached", e.Limit)
+}
+
+// Error отвечает клиенту статусом, соответствующим ошибке Reserve
+func Error(w http.ResponseWriter, err error) {
+	var limit *LimitError
+	switch {
+	case errors.As(err, &limit) && limit.Kind == "daily":
+		w.Header().Set("Retry-After", "3600")
+		problem.Write(w, http.StatusTooManyRequests, problem.CodeQuotaExceeded, "Quota exceeded: "+err.Error())
+	case errors.As(err, &limit):
+		problem.Write(w, http.StatusConflict, problem.CodeQuotaExceeded, "Quota exceeded: "+err.Error())
+	default:
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error checking quota")
+	}
+}
+
+var usageFile string = "quota_usage.json"
+
+// mu сериализует проверку и запись журнала созданий в quota_usage.json
+var mu sync.Mutex
+
+// loadLog читает журнал созданий: ID пользователя -> время созданий за последние сутки.
+// Журнал нужен, чтобы удаление вакансии не возвращало дневной лимит.
+func loadLog() (map[string][]time.Time, error) {
+	data, err := os.ReadFile(usageFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string][]time.Time{}, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла %s: %w", usageFile, err)
+	}
+	log := map[string][]time.Time{}
+	if len(data) == 0 {
+		return log, nil
+	}
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, fmt.Errorf("ошибка разбора JSON из файла %s: %w", usageFile, err)
+	}
+	return log, nil
+}
+
+func saveLog(log map[string][]time.Time) error {
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования в JSON: %w", err)
+	}
+	if err := os.WriteFile(usageFile, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", usageFile, err)
+	}
+	return nil
+}
+
+// recent оставляет записи журнала не старше суток
+func recent(times []time.Time, now time.Time) []time.Time {
+	out := []time.Time{}
+	for _, t := range times {
+		if now.Sub(t) < 24*time.Hour {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func exceeds(used, limit int) bool {
+	return limit != Unlimited && used > limit
+}
+
+// Reservation - принятая операция. Если сохранить ее результат не удалось,
+// резерв отменяется через Cancel.
+type Reservation struct {
+	userID string
+	at     time.Time
+}
+
+// Reserve проверяет, что операция укладывается в план пользователя, и для новой вакансии
+// записывает ее в дневной журнал. Вызывается под блокировкой данных, по которым считается used.
+func Reserve(userID string, used Usage, req Request) (*Reservation, error) {
+	_, plan, err := PlanOf(userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Activate || req.Create {
+		if exceeds(used.Active+1, plan.MaxActive) {
+			return nil, &LimitError{Kind: "active", Limit: plan.MaxActive}
+		}
+	}
+	if req.Feature && exceeds(used.Featured+1, plan.FeaturedSlots) {
+		return nil, &LimitError{Kind: "featured", Limit: plan.FeaturedSlots}
+	}
+	if !req.Create {
+		return &Reservation{}, nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	log, err := loadLog()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	log[userID] = recent(log[userID], now)
+	if exceeds(len(log[userID])+1, plan.MaxPerDay) {
+		return nil, &LimitError{Kind: "daily", Limit: plan.MaxPerDay}
+	}
+	log[userID] = append(log[userID], now)
+	if err := saveLog(log); err != nil {
+		return nil, err
+	}
+	return &Reservation{userID: userID, at: now}, nil
+}
+
+// Cancel возвращает зарезервированное место в дневном лимите
+func (r *Reservation) Cancel() {
+	if r == nil || r.userID == "" {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	log, err := loadLog()
+	if err != nil {
+		fmt.Printf("Ошибка отмены резерва квоты: %v\n", err)
+		return
+	}
+	times := log[r.userID][:0]
+	for _, t := range log[r.userID] {
+		if !t.Equal(r.at) {
+			times = append(times, t)
+		}
+	}
+	log[r.userID] = times
+	if err := saveLog(log); err != nil {
+		fmt.Printf("Ошибка отмены резерва квоты: %v\n", err)
+	}
+}
+
+// Remaining - остаток квоты по каждому лимиту, Unlimited - без ограничения
+type Remaining struct {
+	Active   int `json:"active"`
+	Daily    int `json:"daily"`
+	Featured int `json:"featured"`
+}
+
+// Report - план пользователя, использование и остаток
+type Report struct {
+	Plan         string    `json:"plan"`
+	Limits       Plan      `json:"limits"`
+	Used         Usage     `json:"used"`
+	CreatedToday int       `json:"created_today"`
+	Remaining    Remaining `json:"remaining"`
+}
+
+func left(used, limit int) int {
+	if limit == Unlimited {
+		return Unlimited
+	}
+	return max(limit-used, 0)
+}
+
+// Status собирает отчет о квоте пользователя по текущему использованию
+func Status(userID string, used Usage) (Report, error) {
+	name, plan, err := PlanOf(userID)
+	if err != nil {
+		return Report{}, err
+	}
+	log, err := loadLog()
+	if err != nil {
+		return Report{}, err
+	}
+	today := len(recent(log[userID], time.Now()))
+	return Report{
+		Plan:         name,
+		Limits:       plan,
+		Used:         used,
+		CreatedToday: today,
+		Remaining: Remaining{
+			Active:   left(used.Active, plan.MaxActive),
+			Daily:    left(today, plan.MaxPerDay),
+			Featured: left(used.Featured, plan.FeaturedSlots),
+		},
+	}, nil
+}
@@ -0,0 +1,140 @@
+package quota
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"talant/auth"
+	"testing"
+	"time"
+)
+
+// setup переходит во временный каталог с пользователями всех планов
+func setup(t *testing.T) {
+	t.Helper()
+	// Пользователи и журнал квот хранятся в JSON-файлах рабочего каталога
+	t.Chdir(t.TempDir())
+	err := auth.SaveUsers([]auth.User{
+		{Id: "candidate", Role: auth.RoleCandidate},
+		{Id: "employer", Role: auth.RoleEmployer},
+		{Id: "pro", Role: auth.RoleEmployer, Plan: "pro"},
+		{Id: "stale-plan", Role: auth.RoleEmployer, Plan: "gold"},
+		{Id: "admin", Role: auth.RoleAdmin},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReserve(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID string
+		used   Usage
+		req    Request
+		want   *LimitError
+	}{
+		{name: "first job on basic", userID: "candidate", req: Request{Create: true, Activate: true}},
+		{name: "second active job on basic", userID: "candidate", used: Usage{Active: 1},
+			req: Request{Create: true, Activate: true}, want: &LimitError{Kind: "active", Limit: 1}},
+		{name: "last free slot", userID: "employer", used: Usage{Active: 4}, req: Request{Activate: true}},
+		{name: "over active limit", userID: "employer", used: Usage{Active: 5},
+			req: Request{Activate: true}, want: &LimitError{Kind: "active", Limit: 5}},
+		{name: "editing an active job", userID: "employer", used: Usage{Active: 5}, req: Request{}},
+		{name: "no featured slots on free", userID: "employer",
+			req: Request{Feature: true}, want: &LimitError{Kind: "featured", Limit: 0}},
+		{name: "assigned plan wins over role", userID: "pro", used: Usage{Active: 49, Featured: 4},
+			req: Request{Activate: true, Feature: true}},
+		{name: "all featured slots in use", userID: "pro", used: Usage{Featured: 5},
+			req: Request{Feature: true}, want: &LimitError{Kind: "featured", Limit: 5}},
+		{name: "unknown plan falls back to role", userID: "stale-plan", used: Usage{Active: 5},
+			req: Request{Activate: true}, want: &LimitError{Kind: "active", Limit: 5}},
+		{name: "unlimited", userID: "admin", used: Usage{Active: 1000, Featured: 1000},
+			req: Request{Create: true, Activate: true, Feature: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setup(t)
+			_, err := Reserve(tt.userID, tt.used, tt.req)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Reserve: %v", err)
+				}
+				return
+			}
+			var limit *LimitError
+			if !errors.As(err, &limit) || *limit != *tt.want {
+				t.Fatalf("Reserve = %v, want %+v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReserveDaily(t *testing.T) {
+	setup(t)
+	create := Request{Create: true}
+
+	first, err := Reserve("candidate", Usage{}, create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Удаленная вакансия не возвращает место в дневном лимите: used не растет, журнал - да
+	_, err = Reserve("candidate", Usage{}, create)
+	var limit *LimitError
+	if !errors.As(err, &limit) || limit.Kind != "daily" || limit.Limit != 1 {
+		t.Fatalf("second create = %v, want daily limit", err)
+	}
+	if _, err := Reserve("candidate", Usage{}, Request{Activate: true}); err != nil {
+		t.Fatalf("activation does not count toward the daily limit: %v", err)
+	}
+
+	first.Cancel()
+	if _, err := Reserve("candidate", Usage{}, create); err != nil {
+		t.Fatalf("create after Cancel: %v", err)
+	}
+}
+
+func TestReserveDailyWindow(t *testing.T) {
+	setup(t)
+	now := time.Now().UTC()
+	err := saveLog(map[string][]time.Time{"candidate": {now.Add(-25 * time.Hour)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Reserve("candidate", Usage{}, Request{Create: true}); err != nil {
+		t.Fatalf("creations older than a day should not count: %v", err)
+	}
+
+	if err := saveLog(map[string][]time.Time{"candidate": {now.Add(-23 * time.Hour)}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Reserve("candidate", Usage{}, Request{Create: true}); err == nil {
+		t.Fatal("a creation 23 hours ago should still count")
+	}
+}
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		status     int
+		retryAfter string
+	}{
+		{name: "daily", err: &LimitError{Kind: "daily", Limit: 1}, status: http.StatusTooManyRequests, retryAfter: "3600"},
+		{name: "active", err: &LimitError{Kind: "active", Limit: 5}, status: http.StatusConflict},
+		{name: "featured", err: &LimitError{Kind: "featured", Limit: 0}, status: http.StatusConflict},
+		{name: "failure", err: errors.New("disk is full"), status: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			Error(rec, tt.err)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.retryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.retryAfter)
+			}
+		})
+	}
+}
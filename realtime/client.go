@@ -0,0 +1,164 @@
+package realtime
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"talant/auth"
+	"talant/problem"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	writeWait  = 10 * time.Second
+	pongWait   = 60 * time.Second
+	pingPeriod = pongWait * 9 / 10
+	// sendBuffer - сколько событий может ждать отправки, прежде чем клиент считается медленным
+	sendBuffer     = 64
+	maxMessageSize = 4 << 10
+)
+
+// client - одно WebSocket-подключение пользователя
+type client struct {
+	hub    *Hub
+	conn   *websocket.Conn
+	userID string
+	// topics меняется только под h.mu
+	topics map[string]bool
+	send   chan Event
+}
+
+func (c *client) wants(ev Event) bool {
+	if !c.topics[ev.Topic] {
+		return false
+	}
+	return ev.UserID == "" || ev.UserID == c.userID
+}
+
+// command - сообщение от клиента: {"action":"subscribe","topics":["jobs"]}
+type command struct {
+	Action string   `json:"action"`
+	Topics []string `json:"topics"`
+}
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     sameOrigin,
+}
+
+// sameOrigin пропускает запросы без Origin и с тем же хостом. Авторизация идет по куке,
+// поэтому чужим сайтам открывать сокет от имени пользователя нельзя.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// Handler подключает авторизованного пользователя к потоку событий (/ws).
+// Параметры: topics=jobs,messages (по умолчанию все) и last_event_id для досылки
+// пропущенного после переподключения (можно и заголовком Last-Event-ID).
+func Handler(w http.ResponseWriter, r *http.Request) {
+	DefaultHub.ServeWS(w, r)
+}
+
+func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	lastRaw := r.URL.Query().Get("last_event_id")
+	if lastRaw == "" {
+		lastRaw = r.Header.Get("Last-Event-ID")
+	}
+	var lastID int64
+	if lastRaw != "" {
+		if lastID, err = strconv.ParseInt(lastRaw, 10, 64); err != nil || lastID < 0 {
+			problem.Write(w, http.StatusBadRequest, problem.CodeInvalidParameter, "Invalid last_event_id")
+			return
+		}
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	c := &client{hub: h, conn: conn, userID: userID, topics: map[string]bool{}, send: make(chan Event, sendBuffer)}
+	requested := strings.Split(r.URL.Query().Get("topics"), ",")
+	if r.URL.Query().Get("topics") == "" {
+		requested = []string{TopicJobs, TopicApplications, TopicMessages, TopicNotifications}
+	}
+	for _, t := range requested {
+		if topics[t] {
+			c.topics[t] = true
+		}
+	}
+
+	h.register(c, lastID)
+	go c.writePump()
+	c.readPump()
+}
+
+func (c *client) readPump() {
+	defer func() {
+		c.hub.unregister(c)
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		var cmd command
+		if err := c.conn.ReadJSON(&cmd); err != nil {
+			return
+		}
+		switch cmd.Action {
+		case "subscribe":
+			c.hub.subscribe(c, cmd.Topics, true)
+		case "unsubscribe":
+			c.hub.subscribe(c, cmd.Topics, false)
+		}
+	}
+}
+
+func (c *client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case ev, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				// Хаб закрыл очередь: клиент не успевал читать
+				c.conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "slow consumer, reconnect with last_event_id"))
+				return
+			}
+			if err := c.conn.WriteJSON(ev); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package realtime
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Топики, на которые может подписаться клиент
+const (
+	TopicJobs          = "jobs"          // новые и измененные вакансии на доске
+	TopicApplications  = "applications"  // изменения статусов откликов
+	TopicMessages      = "messages"      // новые сообщения в переписке
+	TopicNotifications = "notifications" // новые уведомления
+)
+
+var topics = map[string]bool{
+	TopicJobs:          true,
+	TopicApplications:  true,
+	TopicMessages:      true,
+	TopicNotifications: true,
+}
+
+// Event - событие, отправляемое клиентам. ID растет монотонно,
+// клиент передает последний полученный ID при переподключении.
+type Event struct {
+	ID    int64           `json:"id"`
+	Topic string          `json:"topic"`
+	Type  string          `json:"type"`
+	Data  json.RawMessage `json:"data"`
+	At    time.Time       `json:"at"`
+	// UserID - адресат события; пустой - всем подписчикам топика
+	UserID string `json:"-"`
+}
+
+// historySize - сколько последних событий хранится для повторной отправки
+const historySize = 1000
+
+// Hub рассылает события подключенным клиентам
+type Hub struct {
+	mu      sync.Mutex
+	nextID  int64
+	history []Event
+	clients map[*client]bool
+}
+
+// DefaultHub - хаб, через который публикуют события остальные пакеты
+var DefaultHub = NewHub()
+
+func NewHub() *Hub {
+	return &Hub{clients: map[*client]bool{}}
+}
+
+// Publish отправляет событие всем подписчикам топика (userID == "")
+// или только сессиям одного пользователя
+func Publish(topic, eventType, userID string, data any) {
+	DefaultHub.Publish(topic, eventType, userID, data)
+}
+
+func (h *Hub) Publish(topic, eventType, userID string, data any) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	ev := Event{ID: h.nextID, Topic: topic, Type: eventType, Data: raw, At: time.Now().UTC(), UserID: userID}
+	h.history = append(h.history, ev)
+	if len(h.history) > historySize {
+		h.history = h.history[len(h.history)-historySize:]
+	}
+
+	for c := range h.clients {
+		if c.wants(ev) {
+			h.deliver(c, ev)
+		}
+	}
+}
+
+// deliver кладет событие в очередь клиента, не блокируясь. Клиент, который
+// не успевает читать, отключается: он переподключится с last_event_id и получит пропущенное.
+func (h *Hub) deliver(c *client, ev Event) {
+	select {
+	case c.send <- ev:
+	default:
+		delete(h.clients, c)
+		close(c.send)
+	}
+}
+
+// register подключает клиента и досылает ему события после lastID. Если часть
+// событий уже вытеснена из истории, клиент сначала получает system/resync
+// и должен перезагрузить данные целиком.
+func (h *Hub) register(c *client, lastID int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.clients[c] = true
+	if lastID <= 0 {
+		return
+	}
+	// lastID больше выданных - сервер перезапускался и история начата заново
+	if lastID > h.nextID || (len(h.history) > 0 && h.history[0].ID > lastID+1) {
+		h.deliver(c, Event{Topic: "system", Type: "resync", Data: json.RawMessage(`{}`), At: time.Now().UTC()})
+	}
+	for _, ev := range h.history {
+		if ev.ID > lastID && c.wants(ev) && h.clients[c] {
+			h.deliver(c, ev)
+		}
+	}
+}
+
+func (h *Hub) unregister(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.clients[c] {
+		delete(h.clients, c)
+		close(c.send)
+	}
+}
+
+// subscribe меняет подписки клиента под блокировкой хаба
+func (h *Hub) subscribe(c *client, list []string, on bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, t := range list {
+		if !topics[t] {
+			continue
+		}
+		if on {
+			c.topics[t] = true
+		} else {
+			delete(c.topics, t)
+		}
+	}
+}
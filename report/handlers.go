@@ -0,0 +1,390 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"talant/auth"
+	"talant/notification"
+	"talant/problem"
+	"talant/request"
+	"time"
+)
+
+// MaxCommentLength - максимальная длина комментария к жалобе в символах (правило max в Input)
+const MaxCommentLength = 1000
+
+// Input - поля жалобы
+type Input struct {
+	Reason  string `json:"reason" validate:"required"`
+	Comment string `json:"comment" validate:"trim,max=1000"`
+}
+
+// ResolveInput - поля решения по делу; days - срок блокировки, 0 - срок по умолчанию
+type ResolveInput struct {
+	Decision string `json:"decision" validate:"required,oneof=uphold dismiss"`
+	Note     string `json:"note" validate:"trim"`
+	Sanction string `json:"sanction" validate:"oneof=warn suspend ban"`
+	Days     int    `json:"days" validate:"min=0"`
+}
+
+// SanctionInput - поля санкции без дела
+type SanctionInput struct {
+	Type   string `json:"type" validate:"required,oneof=warn suspend ban"`
+	Reason string `json:"reason" validate:"trim,required"`
+	Days   int    `json:"days" validate:"min=0"`
+}
+
+// Handler возвращает обработчик жалоб на объекты вида kind
+// (POST /job/{id}/report, /ankety/{id}/report, /users/{id}/report, /messages/{id}/report).
+// Поля: reason - код из Reasons, comment - пояснение, обязательное для other.
+func Handler(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		userID, err := auth.UserIDFromRequest(r)
+		if err != nil {
+			auth.Error(w, err)
+			return
+		}
+
+		var in Input
+		if _, err := request.Decode(w, r, &in); err != nil {
+			request.WriteError(w, err)
+			return
+		}
+		err = request.Validate(&in)
+		if _, ok := Reasons[in.Reason]; in.Reason != "" && !ok {
+			err = request.Join(err, request.Invalid("reason", "must be one of: "+strings.Join(reasonCodes(), ", ")))
+		}
+		if in.Reason == ReasonOther && in.Comment == "" {
+			err = request.Join(err, request.Invalid("comment", "is required for reason other"))
+		}
+		if err != nil {
+			request.WriteError(w, err)
+			return
+		}
+		reason, comment := in.Reason, in.Comment
+
+		rep := Report{ReporterID: userID, Reason: reason, Comment: comment, CreatedAt: time.Now().UTC()}
+		c, err := Submit(kind, r.PathValue("id"), rep)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			problem.Write(w, http.StatusNotFound, problem.CodeNotFound, "Not found")
+			return
+		case errors.Is(err, ErrOwn):
+			problem.Write(w, http.StatusBadRequest, problem.CodeBadRequest, "You cannot report your own content")
+			return
+		case errors.Is(err, ErrDuplicate):
+			problem.Write(w, http.StatusConflict, problem.CodeAlreadyReported, "You have already reported this")
+			return
+		case err != nil:
+			fmt.Printf("Ошибка сохранения жалобы: %v\n", err)
+			problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving report")
+			return
+		}
+
+		// Жалующемуся не показываем чужие жалобы из дела
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{"case_id": c.Id, "kind": kind, "target_id": c.TargetID, "report": rep})
+	}
+}
+
+// reasonCodes возвращает коды причин жалобы по алфавиту
+func reasonCodes() []string {
+	codes := make([]string, 0, len(Reasons))
+	for code := range Reasons {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// adminFrom возвращает ID администратора или отвечает ошибкой
+func adminFrom(w http.ResponseWriter, r *http.Request) (string, bool) {
+	adminID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return "", false
+	}
+	if auth.RoleOf(adminID) != auth.RoleAdmin {
+		problem.Write(w, http.StatusForbidden, problem.CodeAdminOnly, "Admin only")
+		return "", false
+	}
+	return adminID, true
+}
+
+// CasesHandler возвращает дела по жалобам (GET /admin/reports), начиная с дел с наибольшим
+// числом жалоб. Параметры: state (по умолчанию open), kind. Доступно только администраторам.
+func CasesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if _, ok := adminFrom(w, r); !ok {
+		return
+	}
+
+	state := r.URL.Query().Get("state")
+	if state == "" {
+		state = CaseOpen
+	}
+	kind := r.URL.Query().Get("kind")
+
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading reports")
+		return
+	}
+	result := []Case{}
+	for _, c := range list {
+		if c.State == state && (kind == "" || c.Kind == kind) {
+			result = append(result, c)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if len(result[i].Reports) != len(result[j].Reports) {
+			return len(result[i].Reports) > len(result[j].Reports)
+		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// CaseHandler возвращает дело по жалобам вместе с санкциями, уже примененными
+// к автору объекта (GET /admin/reports/{id})
+func CaseHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if _, ok := adminFrom(w, r); !ok {
+		return
+	}
+
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading reports")
+		return
+	}
+	for _, c := range list {
+		if c.Id != r.PathValue("id") {
+			continue
+		}
+		history, err := sanctionsOf(c.OwnerID)
+		if err != nil {
+			problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading sanctions")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"case": c, "owner_sanctions": history})
+		return
+	}
+	problem.Write(w, http.StatusNotFound, problem.CodeCaseNotFound, "Case not found")
+}
+
+// ResolveHandler закрывает дело (POST /admin/reports/{id}/resolve).
+// Поля: decision - uphold (объект остается скрытым) или dismiss (объект снова показывается),
+// note - решение для автора, обязательно при uphold; sanction - warn, suspend или ban
+// для автора объекта, days - срок блокировки для suspend.
+func ResolveHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	adminID, ok := adminFrom(w, r)
+	if !ok {
+		return
+	}
+
+	var in ResolveInput
+	if _, err := request.Decode(w, r, &in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	err := request.Validate(&in)
+	if in.Decision == "uphold" && in.Note == "" {
+		err = request.Join(err, request.Invalid("note", "is required to uphold"))
+	}
+	if in.Decision == "dismiss" && in.Sanction != "" {
+		err = request.Join(err, request.Invalid("sanction", "is allowed only to uphold"))
+	}
+	if err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	decision, note, sanction, days := in.Decision, in.Note, in.Sanction, in.Days
+
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading reports")
+		return
+	}
+	var c *Case
+	for i := range list {
+		if list[i].Id == r.PathValue("id") {
+			c = &list[i]
+			break
+		}
+	}
+	if c == nil {
+		problem.Write(w, http.StatusNotFound, problem.CodeCaseNotFound, "Case not found")
+		return
+	}
+	if c.State != CaseOpen {
+		problem.Write(w, http.StatusConflict, problem.CodeConflict, "Case is already resolved")
+		return
+	}
+	if sanction != "" && auth.RoleOf(c.OwnerID) == auth.RoleAdmin {
+		problem.Write(w, http.StatusForbidden, problem.CodeForbidden, ErrProtected.Error())
+		return
+	}
+
+	hide := targets[c.Kind].Hide
+	hidden := decision == "uphold"
+	if hide != nil && c.Hidden != hidden {
+		if err := hide(c.TargetID, hidden); err != nil {
+			fmt.Printf("Ошибка скрытия объекта жалобы: %v\n", err)
+			problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving decision")
+			return
+		}
+		c.Hidden = hidden
+	}
+
+	if sanction != "" {
+		s, err := Impose(Sanction{UserID: c.OwnerID, Type: sanction, Reason: note, CaseID: c.Id, IssuedBy: adminID}, days)
+		if err != nil {
+			fmt.Printf("Ошибка применения санкции: %v\n", err)
+			problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving sanction")
+			return
+		}
+		c.SanctionID = s.Id
+	}
+
+	now := time.Now().UTC()
+	c.State = CaseDismissed
+	if hidden {
+		c.State = CaseUpheld
+	}
+	c.Note = note
+	c.ResolvedBy = adminID
+	c.ResolvedAt = &now
+	if err := Save(list); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
+		return
+	}
+
+	body := "Нарушений не обнаружено"
+	if hidden {
+		body = "Нарушение подтверждено, мы приняли меры. Спасибо!"
+	}
+	for _, rep := range c.Reports {
+		if err := notification.Send(rep.ReporterID, notification.EventReport, "Жалоба рассмотрена", body, ""); err != nil {
+			fmt.Printf("Ошибка отправки уведомления: %v\n", err)
+		}
+	}
+	if hidden && hide != nil {
+		err := notification.Send(c.OwnerID, notification.EventReport, "Публикация снята по жалобам", "Причина: "+note, linkOf(*c))
+		if err != nil {
+			fmt.Printf("Ошибка отправки уведомления: %v\n", err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// sanctionsOf возвращает санкции пользователя, начиная с последней
+func sanctionsOf(userID string) ([]Sanction, error) {
+	list, err := LoadSanctions()
+	if err != nil {
+		return nil, err
+	}
+	result := []Sanction{}
+	for _, s := range list {
+		if s.UserID == userID {
+			result = append(result, s)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return result, nil
+}
+
+// SanctionsHandler управляет санкциями пользователя (/admin/users/{id}/sanctions):
+// GET - история, POST - новая санкция без дела (поля type, reason, days),
+// DELETE - досрочное снятие блокировок. Доступно только администраторам.
+func SanctionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	adminID, ok := adminFrom(w, r)
+	if !ok {
+		return
+	}
+
+	userID := r.PathValue("id")
+	user, err := auth.GetUser(userID)
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading users")
+		return
+	}
+	if user == nil {
+		problem.Write(w, http.StatusNotFound, problem.CodeUserNotFound, "User not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		history, err := sanctionsOf(userID)
+		if err != nil {
+			problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading sanctions")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(history)
+
+	case http.MethodPost:
+		var in SanctionInput
+		if _, err := request.Decode(w, r, &in); err != nil {
+			request.WriteError(w, err)
+			return
+		}
+		if err := request.Validate(&in); err != nil {
+			request.WriteError(w, err)
+			return
+		}
+		s, err := Impose(Sanction{UserID: userID, Type: in.Type, Reason: in.Reason, IssuedBy: adminID}, in.Days)
+		if errors.Is(err, ErrProtected) {
+			problem.Write(w, http.StatusForbidden, problem.CodeForbidden, err.Error())
+			return
+		}
+		if err != nil {
+			problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving sanction")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(s)
+
+	case http.MethodDelete:
+		lifted, err := Lift(userID, adminID)
+		if err != nil {
+			problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving sanctions")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]int{"lifted": lifted})
+	}
+}
@@ -0,0 +1,231 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"talant/auth"
+	"talant/notification"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Виды объектов, на которые можно пожаловаться
+const (
+	KindJob     = "job"
+	KindAnkety  = "ankety"
+	KindUser    = "user"
+	KindMessage = "message"
+)
+
+// Reasons - коды причин жалобы и их описания
+var Reasons = map[string]string{
+	"scam":           "мошенничество",
+	"fake":           "фальшивая вакансия или анкета",
+	"spam":           "спам или реклама",
+	"offensive":      "оскорбления или непристойное содержание",
+	"discrimination": "дискриминация",
+	ReasonOther:      "другое",
+}
+
+// ReasonOther требует пояснения в комментарии
+const ReasonOther = "other"
+
+// HideThreshold - после жалоб стольких разных пользователей объект скрывается
+// до решения администратора
+const HideThreshold = 3
+
+// Состояния дела по жалобам
+const (
+	CaseOpen      = "open"      // ждет решения администратора
+	CaseUpheld    = "upheld"    // нарушение подтверждено, объект остается скрытым
+	CaseDismissed = "dismissed" // нарушений нет, объект снова показывается
+)
+
+// Report - жалоба одного пользователя
+type Report struct {
+	ReporterID string    `json:"reporter_id"`
+	Reason     string    `json:"reason"`
+	Comment    string    `json:"comment,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Case - дело по объекту: жалобы разных пользователей на один объект собираются вместе
+type Case struct {
+	Id        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	TargetID  string    `json:"target_id"`
+	OwnerID   string    `json:"owner_id"`
+	State     string    `json:"state"`
+	Reports   []Report  `json:"reports"`
+	Hidden    bool      `json:"hidden"`
+	CreatedAt time.Time `json:"created_at"`
+	// Note, SanctionID, ResolvedBy и ResolvedAt заполняются решением администратора
+	Note       string     `json:"note,omitempty"`
+	SanctionID string     `json:"sanction_id,omitempty"`
+	ResolvedBy string     `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// reported сообщает, жаловался ли пользователь в этом деле
+func (c Case) reported(userID string) bool {
+	for _, rep := range c.Reports {
+		if rep.ReporterID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+var casesFile string = "reports.json"
+
+// mu сериализует изменения reports.json
+var mu sync.Mutex
+
+func Load() ([]Case, error) {
+	data, err := os.ReadFile(casesFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Case{}, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла %s: %w", casesFile, err)
+	}
+	if len(data) == 0 {
+		return []Case{}, nil
+	}
+
+	var list []Case
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("ошибка разбора JSON из файла %s: %w", casesFile, err)
+	}
+	return list, nil
+}
+
+func Save(list []Case) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования в JSON: %w", err)
+	}
+	if err := os.WriteFile(casesFile, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", casesFile, err)
+	}
+	return nil
+}
+
+// Target - как найти и скрыть объект жалобы определенного вида
+type Target struct {
+	// Owner возвращает автора объекта или "", если объекта нет или жалующийся его не видит
+	Owner func(id, reporterID string) (string, error)
+	// Hide скрывает объект или возвращает его в показ; nil - объект не скрывается
+	Hide func(id string, hidden bool) error
+}
+
+// targets - по виду объекта. Вакансии, анкеты и сообщения регистрируются в main
+// через OnTarget, чтобы не импортировать их пакеты отсюда.
+var targets = map[string]Target{
+	KindUser: {Owner: userOwner},
+}
+
+// OnTarget регистрирует поиск и скрытие объектов вида kind
+func OnTarget(kind string, t Target) {
+	targets[kind] = t
+}
+
+// userOwner - жалоба на пользователя адресована ему самому
+func userOwner(id, reporterID string) (string, error) {
+	user, err := auth.GetUser(id)
+	if err != nil || user == nil {
+		return "", err
+	}
+	return user.Id, nil
+}
+
+var (
+	ErrNotFound  = errors.New("target not found")
+	ErrOwn       = errors.New("you cannot report yourself")
+	ErrDuplicate = errors.New("already reported")
+)
+
+var kindLinks = map[string]string{KindJob: "/job/", KindAnkety: "/ankety/"}
+
+// Submit регистрирует жалобу на объект: добавляет ее в открытое дело или открывает новое.
+// Повторная жалоба того же пользователя, пока дело открыто, отклоняется с ErrDuplicate.
+// Набрав HideThreshold жалоб, объект скрывается, а автор получает уведомление.
+func Submit(kind, targetID string, rep Report) (Case, error) {
+	t, ok := targets[kind]
+	if !ok {
+		return Case{}, ErrNotFound
+	}
+	ownerID, err := t.Owner(targetID, rep.ReporterID)
+	if err != nil {
+		return Case{}, err
+	}
+	if ownerID == "" {
+		return Case{}, ErrNotFound
+	}
+	if ownerID == rep.ReporterID {
+		return Case{}, ErrOwn
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		return Case{}, err
+	}
+	var c *Case
+	for i := range list {
+		if list[i].State == CaseOpen && list[i].Kind == kind && list[i].TargetID == targetID {
+			c = &list[i]
+			break
+		}
+	}
+	if c == nil {
+		list = append(list, Case{
+			Id:        uuid.New().String(),
+			Kind:      kind,
+			TargetID:  targetID,
+			OwnerID:   ownerID,
+			State:     CaseOpen,
+			Reports:   []Report{},
+			CreatedAt: rep.CreatedAt,
+		})
+		c = &list[len(list)-1]
+	}
+	if c.reported(rep.ReporterID) {
+		return Case{}, ErrDuplicate
+	}
+	c.Reports = append(c.Reports, rep)
+
+	hidden := false
+	if !c.Hidden && t.Hide != nil && len(c.Reports) >= HideThreshold {
+		if err := t.Hide(targetID, true); err != nil {
+			return Case{}, err
+		}
+		c.Hidden = true
+		hidden = true
+	}
+	if err := Save(list); err != nil {
+		return Case{}, err
+	}
+
+	if hidden {
+		err := notification.Send(c.OwnerID, notification.EventReport, "Публикация скрыта по жалобам",
+			"Администратор проверит жалобы пользователей и примет решение", linkOf(*c))
+		if err != nil {
+			fmt.Printf("Ошибка отправки уведомления: %v\n", err)
+		}
+	}
+	return *c, nil
+}
+
+// linkOf - ссылка на объект дела для уведомлений; у сообщений и пользователей ее нет
+func linkOf(c Case) string {
+	if prefix, ok := kindLinks[c.Kind]; ok {
+		return prefix + c.TargetID
+	}
+	return ""
+}
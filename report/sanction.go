@@ -0,0 +1,169 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"talant/auth"
+	"talant/notification"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Виды санкций
+const (
+	SanctionWarn    = "warn"    // предупреждение, доступ не ограничивается
+	SanctionSuspend = "suspend" // блокировка на срок
+	SanctionBan     = "ban"     // бессрочная блокировка
+)
+
+// DefaultSuspendDays - срок блокировки, если администратор его не указал
+const DefaultSuspendDays = 7
+
+// Sanction - мера, примененная администратором к пользователю
+type Sanction struct {
+	Id        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	Type      string     `json:"type"`
+	Reason    string     `json:"reason"`
+	CaseID    string     `json:"case_id,omitempty"`
+	IssuedBy  string     `json:"issued_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// LiftedAt и LiftedBy - досрочное снятие блокировки
+	LiftedAt *time.Time `json:"lifted_at,omitempty"`
+	LiftedBy string     `json:"lifted_by,omitempty"`
+}
+
+// active сообщает, действует ли блокировка сейчас
+func (s Sanction) active(now time.Time) bool {
+	if s.Type == SanctionWarn || s.LiftedAt != nil {
+		return false
+	}
+	return s.ExpiresAt == nil || now.Before(*s.ExpiresAt)
+}
+
+var sanctionsFile string = "sanctions.json"
+
+// smu сериализует изменения sanctions.json
+var smu sync.Mutex
+
+var ErrProtected = errors.New("admins cannot be sanctioned")
+
+func LoadSanctions() ([]Sanction, error) {
+	data, err := os.ReadFile(sanctionsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Sanction{}, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла %s: %w", sanctionsFile, err)
+	}
+	if len(data) == 0 {
+		return []Sanction{}, nil
+	}
+
+	var list []Sanction
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("ошибка разбора JSON из файла %s: %w", sanctionsFile, err)
+	}
+	return list, nil
+}
+
+func SaveSanctions(list []Sanction) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования в JSON: %w", err)
+	}
+	if err := os.WriteFile(sanctionsFile, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", sanctionsFile, err)
+	}
+	return nil
+}
+
+// Impose применяет санкцию к пользователю и уведомляет его. Блокировка не сокращает
+// уже действующую: из двух сроков остается более поздний, бан перекрывает срок.
+func Impose(s Sanction, days int) (Sanction, error) {
+	user, err := auth.GetUser(s.UserID)
+	if err != nil {
+		return Sanction{}, err
+	}
+	if user == nil {
+		return Sanction{}, ErrNotFound
+	}
+	if user.Role == auth.RoleAdmin {
+		return Sanction{}, ErrProtected
+	}
+
+	s.Id = uuid.New().String()
+	s.CreatedAt = time.Now().UTC()
+	until, banned := user.SuspendedUntil, user.Banned
+	switch s.Type {
+	case SanctionSuspend:
+		if days <= 0 {
+			days = DefaultSuspendDays
+		}
+		expires := s.CreatedAt.AddDate(0, 0, days)
+		s.ExpiresAt = &expires
+		if until == nil || until.Before(expires) {
+			until = &expires
+		}
+	case SanctionBan:
+		banned = true
+	}
+
+	smu.Lock()
+	defer smu.Unlock()
+	list, err := LoadSanctions()
+	if err != nil {
+		return Sanction{}, err
+	}
+	if s.Type != SanctionWarn {
+		if err := auth.Restrict(s.UserID, until, banned); err != nil {
+			return Sanction{}, err
+		}
+	}
+	if err := SaveSanctions(append(list, s)); err != nil {
+		return Sanction{}, err
+	}
+
+	title := "Предупреждение от администрации"
+	switch s.Type {
+	case SanctionSuspend:
+		title = "Аккаунт заблокирован до " + s.ExpiresAt.Format("02.01.2006")
+	case SanctionBan:
+		title = "Аккаунт заблокирован"
+	}
+	if err := notification.Send(s.UserID, notification.EventReport, title, "Причина: "+s.Reason, ""); err != nil {
+		fmt.Printf("Ошибка отправки уведомления: %v\n", err)
+	}
+	return s, nil
+}
+
+// Lift досрочно снимает действующие блокировки пользователя и возвращает их число
+func Lift(userID, adminID string) (int, error) {
+	smu.Lock()
+	defer smu.Unlock()
+	list, err := LoadSanctions()
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	lifted := 0
+	for i := range list {
+		if list[i].UserID == userID && list[i].active(now) {
+			list[i].LiftedAt = &now
+			list[i].LiftedBy = adminID
+			lifted++
+		}
+	}
+	if err := auth.Restrict(userID, nil, false); err != nil {
+		return 0, err
+	}
+	if lifted == 0 {
+		return 0, nil
+	}
+	return lifted, SaveSanctions(list)
+}
@@ -0,0 +1,86 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"talant/problem"
+)
+
+// FieldError - ошибка в одном поле запроса
+type FieldError = problem.FieldError
+
+// Error - ошибка разбора или проверки запроса, статус и код ответа на нее.
+// Все обработчики отвечают на такие ошибки одинаково, см. WriteError.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Fields  []FieldError
+}
+
+func (e *Error) Error() string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = f.Field + ": " + f.Message
+	}
+	return e.Message + ": " + strings.Join(parts, "; ")
+}
+
+func invalid(fields []FieldError) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: problem.CodeValidation, Message: "Invalid request", Fields: fields}
+}
+
+// Invalid - ошибка в значении поля field
+func Invalid(field, message string) *Error {
+	return invalid([]FieldError{{Field: field, Message: message}})
+}
+
+// Require возвращает ошибку со всеми незаполненными обязательными полями или nil.
+// values - пары "имя поля, значение"; строка из одних пробелов считается пустой.
+func Require(values ...string) error {
+	var fields []FieldError
+	for i := 0; i+1 < len(values); i += 2 {
+		if strings.TrimSpace(values[i+1]) == "" {
+			fields = append(fields, FieldError{Field: values[i], Message: "is required"})
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return invalid(fields)
+}
+
+func unsupported() *Error {
+	return &Error{Status: http.StatusUnsupportedMediaType, Code: problem.CodeUnsupportedMediaType,
+		Message: "Use application/json, application/x-www-form-urlencoded or multipart/form-data"}
+}
+
+// bodyError - ошибка чтения тела: превышен MaxBodySize или тело повреждено
+func bodyError(err error) *Error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return &Error{Status: http.StatusRequestEntityTooLarge, Code: problem.CodeBodyTooLarge,
+			Message: fmt.Sprintf("Request body is larger than %d bytes", tooLarge.Limit)}
+	}
+	return malformed("Malformed request body")
+}
+
+func malformed(message string) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: problem.CodeMalformedBody, Message: message}
+}
+
+// WriteError отвечает на ошибку запроса в формате problem+json с ошибками по полям.
+// Ошибки другого типа считаются внутренними.
+func WriteError(w http.ResponseWriter, err error) {
+	var e *Error
+	if errors.As(err, &e) {
+		problem.WriteFields(w, e.Status, e.Code, e.Message, e.Fields)
+		return
+	}
+	problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Internal server error")
+}
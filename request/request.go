@@ -0,0 +1,271 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxBodySize - предельный размер тела запроса с полями. Файлы загружаются
+// отдельными запросами через storage.Receive со своими лимитами.
+const MaxBodySize = 1 << 20
+
+// Fields - имена полей, переданных в запросе. По ним частичная правка
+// отличает непереданное поле от переданного пустым.
+type Fields map[string]bool
+
+// Has сообщает, было ли поле в запросе
+func (f Fields) Has(name string) bool {
+	return f[name]
+}
+
+// Decode читает тело запроса в структуру dst по именам из тегов json.
+// Принимает application/json, application/x-www-form-urlencoded и multipart/form-data;
+// в формах списки и вложенные объекты передаются JSON-строкой. Неизвестные поля - ошибка.
+func Decode(w http.ResponseWriter, r *http.Request, dst any) (Fields, error) {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		// Запрос без тела, например POST без параметров
+		r.Body = http.MaxBytesReader(w, r.Body, MaxBodySize)
+		if n, _ := r.Body.Read(make([]byte, 1)); n > 0 {
+			return nil, unsupported()
+		}
+		return Fields{}, nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, unsupported()
+	}
+
+	switch mediaType {
+	case "application/json":
+		return DecodeJSON(w, r, dst)
+	case "application/x-www-form-urlencoded":
+		r.Body = http.MaxBytesReader(w, r.Body, MaxBodySize)
+		if err := r.ParseForm(); err != nil {
+			return nil, bodyError(err)
+		}
+		return decodeValues(r.PostForm, nil, dst)
+	case "multipart/form-data":
+		r.Body = http.MaxBytesReader(w, r.Body, MaxBodySize)
+		if err := r.ParseMultipartForm(MaxBodySize); err != nil {
+			return nil, bodyError(err)
+		}
+		var files []string
+		for name := range r.MultipartForm.File {
+			files = append(files, name)
+		}
+		return decodeValues(r.MultipartForm.Value, files, dst)
+	default:
+		return nil, unsupported()
+	}
+}
+
+// DecodeWithFiles - Decode для запросов, где вместе с полями загружается файл.
+// Multipart-форму к этому времени уже разобрал storage.Receive: файловые поля
+// files пропускаются, остальные поля читаются в dst как в Decode.
+func DecodeWithFiles(w http.ResponseWriter, r *http.Request, dst any, files ...string) (Fields, error) {
+	if r.MultipartForm == nil {
+		return Decode(w, r, dst)
+	}
+	var unexpected []string
+	for name := range r.MultipartForm.File {
+		if !slices.Contains(files, name) {
+			unexpected = append(unexpected, name)
+		}
+	}
+	return decodeValues(r.MultipartForm.Value, unexpected, dst)
+}
+
+// DecodeJSON читает тело запроса - JSON-объект - в dst. Для структуры
+// неизвестные поля, в том числе во вложенных объектах, считаются ошибкой.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst any) (Fields, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodySize)
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, bodyError(err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil || raw == nil {
+		return nil, malformed("Request body must be a JSON object")
+	}
+
+	fields := Fields{}
+	var errs []FieldError
+	index := fieldIndex(dst)
+	for name := range raw {
+		fields[name] = true
+		if index != nil {
+			if _, ok := index[name]; !ok {
+				errs = append(errs, FieldError{Field: name, Message: "unknown field"})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, invalid(errs)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if index != nil {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(dst); err != nil {
+		return nil, FromJSON(err)
+	}
+	return fields, nil
+}
+
+// FromJSON переводит ошибку разбора JSON в ошибку запроса с именем поля
+func FromJSON(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return Invalid(typeErr.Field, "must be "+typeName(typeErr.Type))
+	}
+	// Неизвестное поле во вложенном объекте: json: unknown field "name"
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		if unquoted, err := strconv.Unquote(name); err == nil {
+			name = unquoted
+		}
+		return Invalid(name, "unknown field")
+	}
+	return malformed("Malformed JSON body")
+}
+
+// decodeValues заполняет структуру dst значениями формы; files - имена
+// файловых полей multipart, которых в структуре быть не может
+func decodeValues(values map[string][]string, files []string, dst any) (Fields, error) {
+	index := fieldIndex(dst)
+	if index == nil {
+		panic("request: Decode target must be a pointer to struct")
+	}
+	v := reflect.ValueOf(dst).Elem()
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := Fields{}
+	var errs []FieldError
+	for _, name := range names {
+		i, ok := index[name]
+		if !ok {
+			errs = append(errs, FieldError{Field: name, Message: "unknown field"})
+			continue
+		}
+		fields[name] = true
+		if len(values[name]) == 0 {
+			continue
+		}
+		if msg := setValue(v.Field(i), values[name][0]); msg != "" {
+			errs = append(errs, FieldError{Field: name, Message: msg})
+		}
+	}
+	sort.Strings(files)
+	for _, name := range files {
+		errs = append(errs, FieldError{Field: name, Message: "unexpected file"})
+	}
+	if len(errs) > 0 {
+		return nil, invalid(errs)
+	}
+	return fields, nil
+}
+
+// setValue записывает строковое значение формы в поле; пустая строка - нулевое значение.
+// Возвращает описание ошибки или пустую строку.
+func setValue(f reflect.Value, raw string) string {
+	if f.Kind() == reflect.String {
+		f.SetString(raw)
+		return ""
+	}
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		f.SetZero()
+		return ""
+	}
+	switch f.Kind() {
+	case reflect.Bool:
+		// "on" отправляет отмеченный чекбокс HTML-формы
+		b, err := strconv.ParseBool(raw)
+		if raw == "on" {
+			b, err = true, nil
+		}
+		if err != nil {
+			return "must be " + typeName(f.Type())
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, f.Type().Bits())
+		if err != nil {
+			return "must be " + typeName(f.Type())
+		}
+		f.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		x, err := strconv.ParseFloat(raw, f.Type().Bits())
+		if err != nil {
+			return "must be " + typeName(f.Type())
+		}
+		f.SetFloat(x)
+	default:
+		dec := json.NewDecoder(strings.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(f.Addr().Interface()); err != nil {
+			return "must be " + typeName(f.Type()) + " in JSON"
+		}
+	}
+	return ""
+}
+
+// fieldIndex возвращает индексы полей структуры по именам из тегов json
+// или nil, если dst - не указатель на структуру
+func fieldIndex(dst any) map[string]int {
+	t := reflect.TypeOf(dst)
+	if t == nil || t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	t = t.Elem()
+	index := map[string]int{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		index[name] = i
+	}
+	return index
+}
+
+// typeName описывает ожидаемый тип значения для сообщения об ошибке
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
@@ -0,0 +1,248 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"talant/problem"
+	"time"
+	"unicode/utf8"
+)
+
+// Validate проверяет структуру по правилам из тегов validate и возвращает
+// ошибку со всеми нарушениями сразу или nil. v - указатель на структуру:
+// правило trim изменяет проверяемые строки.
+//
+// Правила перечисляются через запятую и применяются по порядку:
+//
+//	trim        - убрать пробелы по краям строки
+//	required    - значение не пустое (строка из пробелов считается пустой)
+//	min=N/max=N - длина строки в символах, значение числа или число элементов списка
+//	oneof=a b c - строка из списка; пустая строка допустима, если нет required
+//	email       - адрес электронной почты
+//	url         - абсолютная ссылка http(s)
+//	date        - дата в формате YYYY-MM-DD
+//	inline      - ошибки вложенной структуры называются без ее имени
+//
+// Вложенные структуры и списки структур проверяются всегда, имена полей в ошибках
+// строятся по тегам json: "skills[0].name".
+func Validate(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("request.Validate: want pointer to struct, got %T", v)
+	}
+	var fields []FieldError
+	validateStruct(rv.Elem(), "", &fields)
+	if len(fields) == 0 {
+		return nil
+	}
+	return invalid(fields)
+}
+
+// Join объединяет ошибки проверки в одну со всеми полями. Ошибка другого
+// типа возвращается как есть: это сбой, а не неверный запрос.
+func Join(errs ...error) error {
+	var fields []FieldError
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		var e *Error
+		if !errors.As(err, &e) || e.Code != problem.CodeValidation {
+			return err
+		}
+		fields = append(fields, e.Fields...)
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return invalid(fields)
+}
+
+func validateStruct(sv reflect.Value, prefix string, fields *[]FieldError) {
+	t := sv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		rules := f.Tag.Get("validate")
+		path := prefix + name
+		if hasRule(rules, "inline") {
+			path = strings.TrimSuffix(prefix, ".")
+		}
+
+		fv := sv.Field(i)
+		for _, rule := range splitRules(rules) {
+			if msg := check(fv, rule); msg != "" {
+				*fields = append(*fields, FieldError{Field: path, Message: msg})
+				break
+			}
+		}
+		validateNested(fv, path, fields)
+	}
+}
+
+// validateNested проверяет вложенные структуры и элементы списков
+func validateNested(fv reflect.Value, path string, fields *[]FieldError) {
+	switch fv.Kind() {
+	case reflect.Pointer:
+		if !fv.IsNil() {
+			validateNested(fv.Elem(), path, fields)
+		}
+	case reflect.Struct:
+		prefix := ""
+		if path != "" {
+			prefix = path + "."
+		}
+		validateStruct(fv, prefix, fields)
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() != reflect.Struct {
+			return
+		}
+		for i := 0; i < fv.Len(); i++ {
+			validateStruct(fv.Index(i), fmt.Sprintf("%s[%d].", path, i), fields)
+		}
+	}
+}
+
+func splitRules(rules string) []string {
+	if rules == "" {
+		return nil
+	}
+	return strings.Split(rules, ",")
+}
+
+// Within добавляет к именам полей ошибки проверки префикс элемента списка,
+// например "jobs[2]": так ошибки вложенных объектов называются, как в Validate.
+// Ошибка другого типа возвращается как есть.
+func Within(prefix string, err error) error {
+	var e *Error
+	if !errors.As(err, &e) || e.Code != problem.CodeValidation {
+		return err
+	}
+	fields := make([]FieldError, len(e.Fields))
+	for i, f := range e.Fields {
+		fields[i] = FieldError{Field: prefix + "." + f.Field, Message: f.Message}
+	}
+	return invalid(fields)
+}
+
+// Options возвращает варианты правила oneof поля field структуры v, чтобы другие
+// проверки, например фильтры поиска, принимали те же значения, что и модель.
+// Поле без правила oneof - ошибка программы.
+func Options(v any, field string) []string {
+	f, ok := reflect.TypeOf(v).FieldByName(field)
+	if ok {
+		for _, rule := range splitRules(f.Tag.Get("validate")) {
+			if arg, found := strings.CutPrefix(rule, "oneof="); found {
+				return strings.Fields(arg)
+			}
+		}
+	}
+	panic(fmt.Sprintf("request: %T.%s has no oneof rule", v, field))
+}
+
+func hasRule(rules, name string) bool {
+	for _, rule := range splitRules(rules) {
+		if rule == name {
+			return true
+		}
+	}
+	return false
+}
+
+// check применяет одно правило к значению поля и возвращает текст нарушения или ""
+func check(fv reflect.Value, rule string) string {
+	name, arg, _ := strings.Cut(rule, "=")
+	switch name {
+	case "trim":
+		if fv.Kind() == reflect.String && fv.CanSet() {
+			fv.SetString(strings.TrimSpace(fv.String()))
+		}
+	case "required":
+		if fv.IsZero() || (fv.Kind() == reflect.String && strings.TrimSpace(fv.String()) == "") {
+			return "is required"
+		}
+	case "min", "max":
+		limit, err := strconv.Atoi(arg)
+		if err != nil {
+			panic(fmt.Sprintf("request: bad %s rule %q", name, rule))
+		}
+		return checkRange(fv, name, limit)
+	case "oneof":
+		options := strings.Fields(arg)
+		if s := fv.String(); s != "" && !slices.Contains(options, s) {
+			return "must be one of: " + strings.Join(options, ", ")
+		}
+	case "email":
+		if s := fv.String(); s != "" {
+			addr, err := mail.ParseAddress(s)
+			if err != nil || addr.Address != s {
+				return "must be a valid email address"
+			}
+		}
+	case "url":
+		if s := fv.String(); s != "" {
+			u, err := url.Parse(s)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return "must be an http(s) URL"
+			}
+		}
+	case "date":
+		if s := fv.String(); s != "" {
+			if _, err := time.Parse(time.DateOnly, s); err != nil {
+				return "must be in YYYY-MM-DD format"
+			}
+		}
+	case "inline":
+	default:
+		panic(fmt.Sprintf("request: unknown validation rule %q", rule))
+	}
+	return ""
+}
+
+// checkRange сравнивает с пределом длину строки, значение числа или размер списка
+func checkRange(fv reflect.Value, bound string, limit int) string {
+	var n int64
+	var what string
+	switch fv.Kind() {
+	case reflect.String:
+		if fv.String() == "" {
+			// Пустое значение проверяет required
+			return ""
+		}
+		n, what = int64(utf8.RuneCountInString(fv.String())), " characters"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = fv.Int()
+	case reflect.Slice, reflect.Map:
+		n, what = int64(fv.Len()), " items"
+	default:
+		panic(fmt.Sprintf("request: %s rule on %s", bound, fv.Kind()))
+	}
+	if bound == "min" && n < int64(limit) {
+		if what == "" {
+			return fmt.Sprintf("must be at least %d", limit)
+		}
+		return fmt.Sprintf("must have at least %d%s", limit, what)
+	}
+	if bound == "max" && n > int64(limit) {
+		if what == "" {
+			return fmt.Sprintf("must be at most %d", limit)
+		}
+		return fmt.Sprintf("must have at most %d%s", limit, what)
+	}
+	return ""
+}
@@ -0,0 +1,167 @@
+package request
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type skill struct {
+	Name  string `json:"name" validate:"trim,required,max=5"`
+	Level int    `json:"level" validate:"min=1,max=5"`
+}
+
+type contacts struct {
+	Email string `json:"email" validate:"email"`
+}
+
+type profile struct {
+	Name     string   `json:"name" validate:"trim,required,min=2,max=10"`
+	Gender   string   `json:"gender" validate:"oneof=male female"`
+	Age      int      `json:"age" validate:"min=14,max=100"`
+	Tags     []string `json:"tags" validate:"max=2"`
+	Site     string   `json:"site" validate:"url"`
+	Birth    string   `json:"birth_date" validate:"date"`
+	Skills   []skill  `json:"skills"`
+	Contacts contacts `json:"contacts"`
+	Extra    *skill   `json:"extra"`
+	Inline   contacts `json:"inline" validate:"inline"`
+	Secret   string   `json:"-" validate:"required"`
+	Untagged string   `validate:"required"`
+}
+
+// valid возвращает профиль без нарушений; тесты портят в нем одно поле
+func valid() profile {
+	return profile{Name: "Анна", Age: 16, Secret: "x", Untagged: "x"}
+}
+
+// fieldsOf возвращает нарушения из ошибки Validate
+func fieldsOf(t *testing.T, err error) []FieldError {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("err = %v (%T), want *request.Error", err, err)
+	}
+	return e.Fields
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(p *profile)
+		want   []FieldError
+	}{
+		{name: "valid", change: func(p *profile) {}},
+		{name: "required", change: func(p *profile) { p.Name = "" },
+			want: []FieldError{{Field: "name", Message: "is required"}}},
+		{name: "whitespace is empty", change: func(p *profile) { p.Name = "   " },
+			want: []FieldError{{Field: "name", Message: "is required"}}},
+		{name: "min length counts characters", change: func(p *profile) { p.Name = "Я" },
+			want: []FieldError{{Field: "name", Message: "must have at least 2 characters"}}},
+		{name: "max length counts characters", change: func(p *profile) { p.Name = "Александрина" },
+			want: []FieldError{{Field: "name", Message: "must have at most 10 characters"}}},
+		{name: "max length after trim", change: func(p *profile) { p.Name = "  Александр  " }},
+		{name: "number below min", change: func(p *profile) { p.Age = 13 },
+			want: []FieldError{{Field: "age", Message: "must be at least 14"}}},
+		{name: "number above max", change: func(p *profile) { p.Age = 101 },
+			want: []FieldError{{Field: "age", Message: "must be at most 100"}}},
+		{name: "number on the bounds", change: func(p *profile) { p.Age = 100 }},
+		{name: "list too long", change: func(p *profile) { p.Tags = []string{"a", "b", "c"} },
+			want: []FieldError{{Field: "tags", Message: "must have at most 2 items"}}},
+		{name: "oneof", change: func(p *profile) { p.Gender = "other" },
+			want: []FieldError{{Field: "gender", Message: "must be one of: male, female"}}},
+		{name: "oneof is case sensitive", change: func(p *profile) { p.Gender = "Male" },
+			want: []FieldError{{Field: "gender", Message: "must be one of: male, female"}}},
+		{name: "oneof allows empty", change: func(p *profile) { p.Gender = "" }},
+		{name: "url", change: func(p *profile) { p.Site = "ftp://example.com" },
+			want: []FieldError{{Field: "site", Message: "must be an http(s) URL"}}},
+		{name: "url without host", change: func(p *profile) { p.Site = "https://" },
+			want: []FieldError{{Field: "site", Message: "must be an http(s) URL"}}},
+		{name: "date", change: func(p *profile) { p.Birth = "01.02.2008" },
+			want: []FieldError{{Field: "birth_date", Message: "must be in YYYY-MM-DD format"}}},
+		{name: "impossible date", change: func(p *profile) { p.Birth = "2008-02-30" },
+			want: []FieldError{{Field: "birth_date", Message: "must be in YYYY-MM-DD format"}}},
+		{name: "nested struct", change: func(p *profile) { p.Contacts.Email = "not-an-email" },
+			want: []FieldError{{Field: "contacts.email", Message: "must be a valid email address"}}},
+		{name: "email with display name", change: func(p *profile) { p.Contacts.Email = "Anna <a@b.io>" },
+			want: []FieldError{{Field: "contacts.email", Message: "must be a valid email address"}}},
+		{name: "list of structs", change: func(p *profile) {
+			p.Skills = []skill{{Name: "Go", Level: 3}, {Name: " ", Level: 0}}
+		}, want: []FieldError{
+			{Field: "skills[1].name", Message: "is required"},
+			{Field: "skills[1].level", Message: "must be at least 1"},
+		}},
+		{name: "pointer to struct", change: func(p *profile) { p.Extra = &skill{Name: "Kotlin", Level: 1} },
+			want: []FieldError{{Field: "extra.name", Message: "must have at most 5 characters"}}},
+		{name: "inline struct", change: func(p *profile) { p.Inline.Email = "bad" },
+			want: []FieldError{{Field: "email", Message: "must be a valid email address"}}},
+		{name: "untagged field uses Go name", change: func(p *profile) { p.Untagged = "" },
+			want: []FieldError{{Field: "Untagged", Message: "is required"}}},
+		{name: "json:\"-\" is skipped", change: func(p *profile) { p.Secret = "" }},
+		{name: "every violation at once", change: func(p *profile) {
+			p.Name, p.Age, p.Gender = "", 0, "x"
+		}, want: []FieldError{
+			{Field: "name", Message: "is required"},
+			{Field: "gender", Message: "must be one of: male, female"},
+			{Field: "age", Message: "must be at least 14"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid()
+			tt.change(&p)
+			got := fieldsOf(t, Validate(&p))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTrims(t *testing.T) {
+	p := valid()
+	p.Name = "  Анна \n"
+	p.Skills = []skill{{Name: " Go ", Level: 1}}
+	if err := Validate(&p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "Анна" || p.Skills[0].Name != "Go" {
+		t.Errorf("trim: name = %q, skill = %q", p.Name, p.Skills[0].Name)
+	}
+}
+
+func TestValidateNeedsPointer(t *testing.T) {
+	if err := Validate(valid()); err == nil {
+		t.Error("Validate(struct) = nil, want error")
+	}
+}
+
+func TestJoinAndWithin(t *testing.T) {
+	err := Join(nil, Invalid("a", "is required"), Within("jobs[2]", Invalid("title", "is required")))
+	want := []FieldError{{Field: "a", Message: "is required"}, {Field: "jobs[2].title", Message: "is required"}}
+	if got := fieldsOf(t, err); !reflect.DeepEqual(got, want) {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+	if Join(nil, nil) != nil {
+		t.Error("Join(nil, nil) != nil")
+	}
+	failure := errors.New("disk is full")
+	if got := Join(Invalid("a", "is required"), failure); got != failure {
+		t.Errorf("Join with a non-validation error = %v, want it as is", got)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	if got := Options(profile{}, "Gender"); !reflect.DeepEqual(got, []string{"male", "female"}) {
+		t.Errorf("Options = %v", got)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Options on a field without oneof should panic")
+		}
+	}()
+	Options(profile{}, "Name")
+}
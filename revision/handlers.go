@@ -0,0 +1,84 @@
+package revision
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"talant/problem"
+)
+
+// WriteList отвечает списком ревизий объекта, начиная с последней.
+// Права на просмотр проверяет вызывающий обработчик.
+func WriteList(w http.ResponseWriter, kind, objectID string) {
+	list, err := List(kind, objectID)
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading revisions")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// WriteDiff отвечает изменениями полей между ревизиями из параметров from и to.
+// Без to сравнение идет с последней ревизией, без from - с предшествующей to.
+func WriteDiff(w http.ResponseWriter, r *http.Request, kind, objectID string) {
+	to, ok := numberParam(w, r, "to")
+	if !ok {
+		return
+	}
+	toRev, err := Get(kind, objectID, to)
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading revisions")
+		return
+	}
+	if toRev == nil {
+		problem.Write(w, http.StatusNotFound, problem.CodeRevisionNotFound, "Revision not found")
+		return
+	}
+
+	from, ok := numberParam(w, r, "from")
+	if !ok {
+		return
+	}
+	if from == 0 {
+		from = toRev.Number - 1
+	}
+	fromRev, err := Get(kind, objectID, from)
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading revisions")
+		return
+	}
+	if fromRev == nil || from == 0 {
+		problem.Write(w, http.StatusNotFound, problem.CodeRevisionNotFound, "Revision not found")
+		return
+	}
+
+	changes, err := Diff(*fromRev, *toRev)
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error comparing revisions")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(DiffResult{From: fromRev.Number, To: toRev.Number, Changes: changes})
+}
+
+// DiffResult - ответ WriteDiff: номера сравниваемых ревизий и изменения между ними
+type DiffResult struct {
+	From    int      `json:"from"`
+	To      int      `json:"to"`
+	Changes []Change `json:"changes"`
+}
+
+// numberParam читает номер ревизии из параметра запроса; 0 - параметр не передан
+func numberParam(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		problem.Write(w, http.StatusBadRequest, problem.CodeInvalidParameter, name+" must be a revision number")
+		return 0, false
+	}
+	return n, true
+}
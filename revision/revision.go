@@ -0,0 +1,226 @@
+package revision
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Виды объектов, для которых ведется история
+const (
+	KindJob    = "job"
+	KindAnkety = "ankety"
+)
+
+// Revision - состояние редактируемых полей объекта после очередной правки
+type Revision struct {
+	Kind      string    `json:"kind"`
+	ObjectID  string    `json:"object_id"`
+	Number    int       `json:"number"`
+	AuthorID  string    `json:"author_id"`
+	CreatedAt time.Time `json:"created_at"`
+	// RestoredFrom - номер ревизии, из которой восстановлено содержимое
+	RestoredFrom int             `json:"restored_from,omitempty"`
+	Content      json.RawMessage `json:"content"`
+}
+
+// Change - изменение одного поля между ревизиями; null в From - поля не было
+type Change struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from"`
+	To    json.RawMessage `json:"to"`
+}
+
+var revisionsFile string = "revisions.json"
+
+// mu сериализует изменения revisions.json
+var mu sync.Mutex
+
+func Load() ([]Revision, error) {
+	data, err := os.ReadFile(revisionsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Revision{}, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла %s: %w", revisionsFile, err)
+	}
+	if len(data) == 0 {
+		return []Revision{}, nil
+	}
+
+	var list []Revision
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("ошибка разбора JSON из файла %s: %w", revisionsFile, err)
+	}
+	return list, nil
+}
+
+func Save(list []Revision) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования в JSON: %w", err)
+	}
+	if err := os.WriteFile(revisionsFile, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", revisionsFile, err)
+	}
+	return nil
+}
+
+// Snapshot кодирует объект в содержимое ревизии без служебных полей omit
+// (имена JSON-полей), которые не редактируются пользователем
+func Snapshot(v any, omit ...string) (json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for _, name := range omit {
+		delete(fields, name)
+	}
+	return json.Marshal(fields)
+}
+
+// history возвращает индексы ревизий объекта в list по возрастанию номера
+func history(list []Revision, kind, objectID string) []int {
+	var idx []int
+	for i, rev := range list {
+		if rev.Kind == kind && rev.ObjectID == objectID {
+			idx = append(idx, i)
+		}
+	}
+	sort.Slice(idx, func(a, b int) bool { return list[idx[a]].Number < list[idx[b]].Number })
+	return idx
+}
+
+// Record сохраняет новую ревизию с очередным номером. Если содержимое не изменилось
+// с последней ревизии, новая не создается и возвращается последняя.
+func Record(rev Revision) (Revision, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		return Revision{}, err
+	}
+	rev.Number = 1
+	if idx := history(list, rev.Kind, rev.ObjectID); len(idx) > 0 {
+		last := list[idx[len(idx)-1]]
+		if sameJSON(last.Content, rev.Content) {
+			return last, nil
+		}
+		rev.Number = last.Number + 1
+	}
+	if err := Save(append(list, rev)); err != nil {
+		return Revision{}, err
+	}
+	return rev, nil
+}
+
+// Baseline сохраняет ревизию 1 для объекта, созданного до ведения истории.
+// Если у объекта уже есть ревизии, ничего не делает.
+func Baseline(rev Revision) error {
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		return err
+	}
+	if len(history(list, rev.Kind, rev.ObjectID)) > 0 {
+		return nil
+	}
+	rev.Number = 1
+	return Save(append(list, rev))
+}
+
+// List возвращает ревизии объекта, начиная с последней
+func List(kind, objectID string) ([]Revision, error) {
+	list, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	result := []Revision{}
+	idx := history(list, kind, objectID)
+	for i := len(idx) - 1; i >= 0; i-- {
+		result = append(result, list[idx[i]])
+	}
+	return result, nil
+}
+
+// Get возвращает ревизию объекта по номеру; number 0 - последняя. nil, если ее нет.
+func Get(kind, objectID string, number int) (*Revision, error) {
+	list, err := List(kind, objectID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if number == 0 || list[i].Number == number {
+			return &list[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Forget удаляет историю объекта, например вместе с анкетой кандидата
+func Forget(kind, objectID string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		return err
+	}
+	kept := list[:0]
+	for _, rev := range list {
+		if rev.Kind != kind || rev.ObjectID != objectID {
+			kept = append(kept, rev)
+		}
+	}
+	if len(kept) == len(list) {
+		return nil
+	}
+	return Save(kept)
+}
+
+// Diff сравнивает содержимое двух ревизий по полям верхнего уровня
+func Diff(from, to Revision) ([]Change, error) {
+	var a, b map[string]json.RawMessage
+	if err := json.Unmarshal(from.Content, &a); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(to.Content, &b); err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
+	for name := range a {
+		names[name] = true
+	}
+	for name := range b {
+		names[name] = true
+	}
+
+	changes := []Change{}
+	for name := range names {
+		if !sameJSON(a[name], b[name]) {
+			changes = append(changes, Change{Field: name, From: a[name], To: b[name]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+// sameJSON сравнивает значения без учета пробелов в записи
+func sameJSON(a, b json.RawMessage) bool {
+	var ca, cb bytes.Buffer
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
@@ -0,0 +1,349 @@
+package school
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"talant/auth"
+	"talant/problem"
+	"talant/request"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Input - поля заведения; aliases - сокращения JSON-массивом или через запятую
+type Input struct {
+	Name    string `json:"name" validate:"trim"`
+	Aliases string `json:"aliases"`
+	City    string `json:"city" validate:"trim"`
+}
+
+// CurateInput - положение вакансии на доске: pinned, hidden или пустое
+type CurateInput struct {
+	State string `json:"state" validate:"oneof=pinned hidden"`
+}
+
+// decodeInput читает поля заведения; name обязателен, если передан или required.
+// При ошибке ответ уже записан.
+func decodeInput(w http.ResponseWriter, r *http.Request, required bool) (Input, []string, request.Fields, bool) {
+	var in Input
+	sent, err := request.Decode(w, r, &in)
+	if err != nil {
+		request.WriteError(w, err)
+		return Input{}, nil, nil, false
+	}
+	err = request.Validate(&in)
+	if in.Name == "" && (required || sent.Has("name")) {
+		err = request.Join(err, request.Invalid("name", "is required"))
+	}
+	aliases, aliasErr := parseAliases(in.Aliases)
+	if err = request.Join(err, aliasErr); err != nil {
+		request.WriteError(w, err)
+		return Input{}, nil, nil, false
+	}
+	return in, aliases, sent, true
+}
+
+// parseAliases принимает сокращения JSON-массивом или через запятую
+func parseAliases(raw string) ([]string, error) {
+	raw = strings.TrimSpace(raw)
+	var list []string
+	if strings.HasPrefix(raw, "[") {
+		if err := json.Unmarshal([]byte(raw), &list); err != nil {
+			return nil, request.Invalid("aliases", "must be a JSON array of strings or a comma-separated list")
+		}
+	} else {
+		list = strings.Split(raw, ",")
+	}
+	aliases := []string{}
+	for _, a := range list {
+		if a = strings.TrimSpace(a); a != "" {
+			aliases = append(aliases, a)
+		}
+	}
+	return aliases, nil
+}
+
+// ListHandler возвращает справочник заведений (GET /schools).
+// С параметром q отдает подсказки по нечеткому совпадению с оценкой сходства.
+func ListHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading schools")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		json.NewEncoder(w).Encode(list)
+		return
+	}
+	limit := 10
+	if n, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && n > 0 && n <= 50 {
+		limit = n
+	}
+	json.NewEncoder(w).Encode(Suggest(list, q, limit))
+}
+
+// OpenHandler возвращает заведение по ID (GET /schools/{id})
+func OpenHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	s, err := Get(r.PathValue("id"))
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading schools")
+		return
+	}
+	if s == nil {
+		problem.Write(w, http.StatusNotFound, problem.CodeSchoolNotFound, "School not found")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
+
+// CreateHandler добавляет заведение в справочник (POST /schools, поля name, aliases, city).
+// Доступно только администраторам площадки.
+func CreateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+	if auth.RoleOf(userID) != auth.RoleAdmin {
+		problem.Write(w, http.StatusForbidden, problem.CodeAdminOnly, "Admin only")
+		return
+	}
+
+	in, aliases, _, ok := decodeInput(w, r, true)
+	if !ok {
+		return
+	}
+	s := School{
+		Id:        uuid.New().String(),
+		Name:      in.Name,
+		Aliases:   aliases,
+		City:      in.City,
+		Admins:    []string{},
+		CreatedAt: time.Now().UTC(),
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading schools")
+		return
+	}
+	if conflict := taken(list, s); conflict != "" {
+		problem.Write(w, http.StatusConflict, problem.CodeAlreadyExists, "Name or alias is already used by "+conflict)
+		return
+	}
+	if err := Save(append(list, s)); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(s)
+}
+
+// taken возвращает название другого заведения, у которого уже есть такое имя или сокращение
+func taken(list []School, s School) string {
+	names := map[string]bool{}
+	for _, n := range append([]string{s.Name}, s.Aliases...) {
+		names[normalize(n)] = true
+	}
+	for _, other := range list {
+		if other.Id == s.Id {
+			continue
+		}
+		for _, n := range append([]string{other.Name}, other.Aliases...) {
+			if names[normalize(n)] {
+				return other.Name
+			}
+		}
+	}
+	return ""
+}
+
+// modify применяет change к заведению под блокировкой, если пользователь может вести его доску.
+// change возвращает http.StatusOK или статус, код и текст ошибки.
+func modify(w http.ResponseWriter, r *http.Request, change func(list []School, s *School) (int, string, string)) (School, bool) {
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return School{}, false
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading schools")
+		return School{}, false
+	}
+	for i := range list {
+		if list[i].Id != r.PathValue("id") {
+			continue
+		}
+		if !list[i].CanCurate(userID) {
+			problem.Write(w, http.StatusForbidden, problem.CodeForbidden, "School admin only")
+			return School{}, false
+		}
+		s := list[i]
+		if status, code, msg := change(list, &s); status != http.StatusOK {
+			problem.Write(w, status, code, msg)
+			return School{}, false
+		}
+		list[i] = s
+		if err := Save(list); err != nil {
+			problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error writing data file")
+			return School{}, false
+		}
+		return s, true
+	}
+	problem.Write(w, http.StatusNotFound, problem.CodeSchoolNotFound, "School not found")
+	return School{}, false
+}
+
+// UpdateHandler меняет название, сокращения и город заведения (PATCH /schools/{id}).
+// Меняются только переданные поля.
+func UpdateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+	in, aliases, sent, ok := decodeInput(w, r, false)
+	if !ok {
+		return
+	}
+
+	s, ok := modify(w, r, func(list []School, s *School) (int, string, string) {
+		if sent.Has("name") {
+			s.Name = in.Name
+		}
+		if sent.Has("aliases") {
+			s.Aliases = aliases
+		}
+		if sent.Has("city") {
+			s.City = in.City
+		}
+		if conflict := taken(list, *s); conflict != "" {
+			return http.StatusConflict, problem.CodeAlreadyExists, "Name or alias is already used by " + conflict
+		}
+		return http.StatusOK, "", ""
+	})
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
+
+// AdminHandler назначает (PUT) или снимает (DELETE) администратора заведения
+// (/schools/{id}/admins/{user_id}). Доступно только администраторам площадки.
+func AdminHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodDelete {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+	if auth.RoleOf(userID) != auth.RoleAdmin {
+		problem.Write(w, http.StatusForbidden, problem.CodeAdminOnly, "Admin only")
+		return
+	}
+	adminID := r.PathValue("user_id")
+	if r.Method == http.MethodPut {
+		user, err := auth.GetUser(adminID)
+		if err != nil {
+			problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading users")
+			return
+		}
+		if user == nil {
+			problem.Write(w, http.StatusNotFound, problem.CodeUserNotFound, "User not found")
+			return
+		}
+	}
+
+	s, ok := modify(w, r, func(_ []School, s *School) (int, string, string) {
+		admins := []string{}
+		for _, id := range s.Admins {
+			if id != adminID {
+				admins = append(admins, id)
+			}
+		}
+		if r.Method == http.MethodPut {
+			admins = append(admins, adminID)
+		}
+		s.Admins = admins
+		return http.StatusOK, "", ""
+	})
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
+
+// CurateHandler задает положение вакансии на доске заведения (PUT /schools/{id}/jobs/{job_id}).
+// Поле state: pinned - закрепить, hidden - скрыть, пустое - вернуть обычный порядок.
+func CurateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var in CurateInput
+	if _, err := request.Decode(w, r, &in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if err := request.Validate(&in); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	state := in.State
+	jobID := r.PathValue("job_id")
+
+	s, ok := modify(w, r, func(_ []School, s *School) (int, string, string) {
+		curation := map[string]string{}
+		for id, st := range s.Curation {
+			curation[id] = st
+		}
+		if state == "" {
+			delete(curation, jobID)
+		} else {
+			curation[jobID] = state
+		}
+		s.Curation = curation
+		return http.StatusOK, "", ""
+	})
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
@@ -0,0 +1,117 @@
+package school
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MatchThreshold - минимальное сходство, при котором ввод считается названием заведения
+const MatchThreshold = 0.8
+
+// SuggestThreshold - минимальное сходство для подсказок при поиске
+const SuggestThreshold = 0.5
+
+// normalize приводит название к виду для сравнения: нижний регистр, ё -> е,
+// без кавычек и знаков препинания, с одиночными пробелами
+func normalize(s string) string {
+	s = strings.ToLower(strings.ReplaceAll(strings.ReplaceAll(s, "ё", "е"), "Ё", "Е"))
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+			space = false
+			continue
+		}
+		space = true
+	}
+	return b.String()
+}
+
+// levenshtein - редакционное расстояние между строками в символах
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// similarity - сходство нормализованных строк от 0 до 1
+func similarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// Score - сходство ввода с названием или одним из сокращений заведения
+func Score(s School, input string) float64 {
+	q := normalize(input)
+	best := 0.0
+	for _, name := range append([]string{s.Name}, s.Aliases...) {
+		n := normalize(name)
+		score := similarity(q, n)
+		// Ввод начинает название: "таганрогский авиационный" для полного названия
+		if len([]rune(q)) >= 4 && strings.HasPrefix(n, q+" ") {
+			score = max(score, SuggestThreshold)
+		}
+		best = max(best, score)
+	}
+	return best
+}
+
+// Find возвращает заведение, которое лучше всего совпадает с вводом, если сходство
+// не ниже MatchThreshold. При равенстве выигрывает заведение, стоящее раньше в справочнике.
+func Find(list []School, input string) (*School, float64) {
+	var found *School
+	best := 0.0
+	for i := range list {
+		if score := Score(list[i], input); score >= MatchThreshold && score > best {
+			found, best = &list[i], score
+		}
+	}
+	return found, best
+}
+
+// Suggestion - вариант заведения для подсказки при вводе
+type Suggestion struct {
+	School
+	Score float64 `json:"score"`
+}
+
+// Suggest возвращает до limit заведений, похожих на ввод, от лучшего к худшему
+func Suggest(list []School, input string, limit int) []Suggestion {
+	out := []Suggestion{}
+	for _, s := range list {
+		if score := Score(s, input); score >= SuggestThreshold {
+			out = append(out, Suggestion{School: s, Score: score})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
@@ -0,0 +1,127 @@
+package school
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"talant/auth"
+	"time"
+)
+
+// Состояния вакансии на доске учебного заведения, которые задают его администраторы
+const (
+	CurationPinned = "pinned" // вакансия закреплена сверху, даже если она для другого заведения
+	CurationHidden = "hidden" // вакансия скрыта с доски
+)
+
+// School - учебное заведение из справочника
+type School struct {
+	Id string `json:"id"`
+	// Name - каноническое название, Aliases - сокращения и варианты написания
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+	City    string   `json:"city,omitempty"`
+	// Admins - пользователи, которые ведут доску вакансий заведения
+	Admins []string `json:"admins"`
+	// Curation - ID вакансии -> pinned или hidden
+	Curation  map[string]string `json:"curation,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+var schoolsFile string = "schools.json"
+
+var ErrUnknownSchool = errors.New("unknown school_id")
+
+// mu сериализует изменения schools.json
+var mu sync.Mutex
+
+func Load() ([]School, error) {
+	data, err := os.ReadFile(schoolsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []School{}, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла %s: %w", schoolsFile, err)
+	}
+	if len(data) == 0 {
+		return []School{}, nil
+	}
+
+	var list []School
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("ошибка разбора JSON из файла %s: %w", schoolsFile, err)
+	}
+	return list, nil
+}
+
+func Save(list []School) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования в JSON: %w", err)
+	}
+	if err := os.WriteFile(schoolsFile, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", schoolsFile, err)
+	}
+	return nil
+}
+
+// Get возвращает заведение по ID или nil, если его нет
+func Get(id string) (*School, error) {
+	list, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if list[i].Id == id {
+			return &list[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// CanCurate сообщает, может ли пользователь вести доску заведения:
+// это администраторы заведения и администраторы площадки
+func (s School) CanCurate(userID string) bool {
+	if auth.RoleOf(userID) == auth.RoleAdmin {
+		return true
+	}
+	for _, id := range s.Admins {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve приводит заведение из формы к справочнику. Явный schoolID должен существовать,
+// иначе название text ищется нечетко. Если совпадения нет, возвращается исходный text без ID.
+func Resolve(schoolID, text string) (id, name string, err error) {
+	list, err := Load()
+	if err != nil {
+		return "", "", err
+	}
+	if schoolID != "" {
+		for _, s := range list {
+			if s.Id == schoolID {
+				return s.Id, s.Name, nil
+			}
+		}
+		return "", "", ErrUnknownSchool
+	}
+	if s, _ := Find(list, text); s != nil {
+		return s.Id, s.Name, nil
+	}
+	return "", text, nil
+}
+
+// Belongs сообщает, относится ли запись с сохраненным schoolID и текстом text к заведению s.
+// Записи без schoolID, созданные до появления справочника, сопоставляются по тексту.
+func Belongs(list []School, s School, schoolID, text string) bool {
+	if schoolID != "" {
+		return schoolID == s.Id
+	}
+	found, _ := Find(list, text)
+	return found != nil && found.Id == s.Id
+}
@@ -0,0 +1,122 @@
+package search
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"talant/job"
+	"talant/notification"
+	"time"
+)
+
+// DigestInterval - как часто собирается ежедневная сводка
+const DigestInterval = 24 * time.Hour
+
+// JobPublished сверяет опубликованную вакансию с сохраненными поисками.
+// Для instant-поисков уведомление уходит сразу, для daily - вакансия откладывается в сводку.
+// О каждой вакансии поиск сообщает один раз, см. SavedSearch.Notified.
+func JobPublished(j job.Job) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	list, err := Load()
+	if err != nil {
+		fmt.Printf("Ошибка загрузки сохраненных поисков: %v\n", err)
+		return
+	}
+
+	changed := false
+	for i, s := range list {
+		if s.UserID == j.UserID || slices.Contains(s.Notified, j.Id) || !s.Matches(j) {
+			continue
+		}
+		if s.Frequency == FrequencyDaily {
+			if !slices.Contains(s.Pending, j.Id) {
+				list[i].Pending = append(list[i].Pending, j.Id)
+				changed = true
+			}
+			continue
+		}
+		err := notification.Send(s.UserID, notification.EventJobAlert,
+			"Новая вакансия по поиску «"+s.Name+"»", j.Title, "/job/"+j.Id)
+		if err != nil {
+			fmt.Printf("Ошибка отправки уведомления: %v\n", err)
+			continue
+		}
+		list[i].Notified = append(list[i].Notified, j.Id)
+		changed = true
+	}
+
+	if changed {
+		if err := Save(list); err != nil {
+			fmt.Printf("Ошибка сохранения поисков: %v\n", err)
+		}
+	}
+}
+
+// SendDigests отправляет сводки по daily-поискам, у которых прошли сутки с прошлой сводки.
+// Ошибка отправки одной сводки не мешает остальным: поиск получит ее при следующей проверке.
+func SendDigests(now time.Time) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	list, err := Load()
+	if err != nil {
+		return err
+	}
+	jobs, err := job.LoadJobs()
+	if err != nil {
+		return err
+	}
+	titles := map[string]string{}
+	for _, j := range jobs {
+		titles[j.Id] = j.Title
+	}
+
+	changed := false
+	for i, s := range list {
+		if s.Frequency != FrequencyDaily || len(s.Pending) == 0 || now.Sub(s.LastDigestAt) < DigestInterval {
+			continue
+		}
+		var lines []string
+		for _, id := range s.Pending {
+			// Вакансия могла быть удалена, пока ждала сводки
+			if title, ok := titles[id]; ok {
+				lines = append(lines, "• "+title)
+			}
+		}
+		if len(lines) > 0 {
+			err := notification.Send(s.UserID, notification.EventJobAlert,
+				fmt.Sprintf("Новые вакансии по поиску «%s»: %d", s.Name, len(lines)),
+				strings.Join(lines, "\n"), "/searches/"+s.Id)
+			if err != nil {
+				// Сводка уйдет на следующей проверке; остальные поиски и уже
+				// отправленные сводки сохраняются как обычно
+				fmt.Printf("Ошибка отправки сводки по поиску %s: %v\n", s.Id, err)
+				continue
+			}
+		}
+		list[i].Notified = append(list[i].Notified, s.Pending...)
+		list[i].Pending = nil
+		list[i].LastDigestAt = now
+		changed = true
+	}
+
+	if changed {
+		return Save(list)
+	}
+	return nil
+}
+
+// StartDigests раз в час проверяет, не пора ли отправить ежедневные сводки
+func StartDigests() {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			if err := SendDigests(now); err != nil {
+				fmt.Printf("Ошибка отправки сводок: %v\n", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,246 @@
+package search
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"talant/auth"
+	"talant/job"
+	"talant/match"
+	"talant/problem"
+	"talant/request"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Input - параметры сохраненного поиска; skills - навыки через запятую
+type Input struct {
+	Name      string `json:"name" validate:"trim,required"`
+	Query     string `json:"query" validate:"trim"`
+	Skills    string `json:"skills"`
+	SalaryMin int    `json:"salary_min" validate:"min=0"`
+	SalaryMax int    `json:"salary_max" validate:"min=0"`
+	JobType   string `json:"job_type"`
+	Frequency string `json:"frequency" validate:"oneof=instant daily"`
+}
+
+// decodeSearch читает и проверяет параметры поиска. При ошибке ответ уже записан.
+func decodeSearch(w http.ResponseWriter, r *http.Request) (Input, bool) {
+	var in Input
+	if _, err := request.Decode(w, r, &in); err != nil {
+		request.WriteError(w, err)
+		return Input{}, false
+	}
+	err := request.Validate(&in)
+	if !job.ValidJobType(in.JobType) {
+		err = request.Join(err, request.Invalid("job_type", "must be one of: "+strings.Join(request.Options(job.Job{}, "JobType"), ", ")))
+	}
+	if in.SalaryMax > 0 && in.SalaryMin > in.SalaryMax {
+		err = request.Join(err, request.Invalid("salary_min", "must not be greater than salary_max"))
+	}
+	if err != nil {
+		request.WriteError(w, err)
+		return Input{}, false
+	}
+	return in, true
+}
+
+// apply переносит параметры поиска в сохраненный поиск
+func (in Input) apply(s *SavedSearch) {
+	s.Name = in.Name
+	s.Query = in.Query
+	s.Skills = match.SplitSkills(in.Skills)
+	if s.Skills == nil {
+		s.Skills = []string{}
+	}
+	s.SalaryMin, s.SalaryMax = in.SalaryMin, in.SalaryMax
+	s.JobType = in.JobType
+	s.Frequency = in.Frequency
+	if s.Frequency == "" {
+		s.Frequency = FrequencyInstant
+	}
+}
+
+// CreateHandler сохраняет новый поиск (POST /searches)
+func CreateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	in, ok := decodeSearch(w, r)
+	if !ok {
+		return
+	}
+
+	s := SavedSearch{
+		Id:        uuid.New().String(),
+		UserID:    userID,
+		CreatedAt: time.Now().UTC(),
+	}
+	s.LastDigestAt = s.CreatedAt
+	in.apply(&s)
+
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading searches")
+		return
+	}
+	if err := Save(append(list, s)); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving search")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(s)
+}
+
+// ListHandler возвращает сохраненные поиски текущего пользователя (GET /searches)
+func ListHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading searches")
+		return
+	}
+	mine := []SavedSearch{}
+	for _, s := range list {
+		if s.UserID == userID {
+			mine = append(mine, s)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mine)
+}
+
+// OpenHandler возвращает сохраненный поиск владельцу (GET /searches/{id})
+func OpenHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading searches")
+		return
+	}
+	for _, s := range list {
+		if s.Id == r.PathValue("id") && s.UserID == userID {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(s)
+			return
+		}
+	}
+	problem.Write(w, http.StatusNotFound, problem.CodeSearchNotFound, "Search not found")
+}
+
+// UpdateHandler заменяет параметры сохраненного поиска (PUT /searches/{id})
+func UpdateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+	in, ok := decodeSearch(w, r)
+	if !ok {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading searches")
+		return
+	}
+	for i := range list {
+		if list[i].Id != r.PathValue("id") || list[i].UserID != userID {
+			continue
+		}
+		s := list[i]
+		in.apply(&s)
+		list[i] = s
+		if err := Save(list); err != nil {
+			problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving search")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s)
+		return
+	}
+	problem.Write(w, http.StatusNotFound, problem.CodeSearchNotFound, "Search not found")
+}
+
+// DeleteHandler удаляет сохраненный поиск (DELETE /searches/{id})
+func DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	list, err := Load()
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading searches")
+		return
+	}
+	remaining := make([]SavedSearch, 0, len(list))
+	found := false
+	for _, s := range list {
+		if s.Id == r.PathValue("id") && s.UserID == userID {
+			found = true
+			continue
+		}
+		remaining = append(remaining, s)
+	}
+	if !found {
+		problem.Write(w, http.StatusNotFound, problem.CodeSearchNotFound, "Search not found")
+		return
+	}
+	if err := Save(remaining); err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving search")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,114 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"talant/job"
+	"talant/match"
+	"time"
+)
+
+// Частота оповещений о новых вакансиях
+const (
+	FrequencyInstant = "instant"
+	FrequencyDaily   = "daily"
+)
+
+// SavedSearch - сохраненный поиск кандидата
+type SavedSearch struct {
+	Id        string   `json:"id"`
+	UserID    string   `json:"user_id"`
+	Name      string   `json:"name"`
+	Query     string   `json:"query"`
+	Skills    []string `json:"skills"`
+	SalaryMin int      `json:"salary_min,omitempty"`
+	SalaryMax int      `json:"salary_max,omitempty"`
+	JobType   string   `json:"job_type,omitempty"`
+	Frequency string   `json:"frequency"`
+	// Pending - ID подходящих вакансий, которые еще не попали в ежедневную сводку
+	Pending []string `json:"pending,omitempty"`
+	// Notified - ID вакансий, о которых уже сообщено; правка, восстановление
+	// или возврат из корзины снова публикуют вакансию, но повторно о ней не сообщают
+	Notified     []string  `json:"notified,omitempty"`
+	LastDigestAt time.Time `json:"last_digest_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+var searchesFile string = "searches.json"
+
+// mu сериализует изменения searches.json
+var mu sync.Mutex
+
+func Load() ([]SavedSearch, error) {
+	data, err := os.ReadFile(searchesFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []SavedSearch{}, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла %s: %w", searchesFile, err)
+	}
+	if len(data) == 0 {
+		return []SavedSearch{}, nil
+	}
+
+	var list []SavedSearch
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("ошибка разбора JSON из файла %s: %w", searchesFile, err)
+	}
+	return list, nil
+}
+
+func Save(list []SavedSearch) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования в JSON: %w", err)
+	}
+	if err := os.WriteFile(searchesFile, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", searchesFile, err)
+	}
+	return nil
+}
+
+// Matches проверяет, подходит ли вакансия под сохраненный поиск.
+// Все слова запроса должны встречаться в названии, компании или описании,
+// все навыки - в навыках вакансии.
+func (s SavedSearch) Matches(j job.Job) bool {
+	if s.JobType != "" && s.JobType != j.JobType {
+		return false
+	}
+
+	text := strings.ToLower(j.Title + " " + j.Company + " " + j.Description)
+	for _, word := range strings.Fields(strings.ToLower(s.Query)) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+
+	jobSkills := map[string]bool{}
+	for _, skill := range match.SplitSkills(j.Skills) {
+		jobSkills[strings.ToLower(skill)] = true
+	}
+	for _, skill := range s.Skills {
+		if !jobSkills[strings.ToLower(skill)] {
+			return false
+		}
+	}
+
+	if s.SalaryMin > 0 || s.SalaryMax > 0 {
+		// Вилка вакансии должна пересекаться с диапазоном поиска
+		lo, hi := match.SalaryRange(j)
+		if hi == 0 {
+			return false
+		}
+		if s.SalaryMin > 0 && hi < s.SalaryMin {
+			return false
+		}
+		if s.SalaryMax > 0 && lo > s.SalaryMax {
+			return false
+		}
+	}
+	return true
+}
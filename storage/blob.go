@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// BlobStore хранит бинарные файлы (резюме, фото, логотипы) по ключу
+type BlobStore interface {
+	// Put сохраняет содержимое и возвращает ключ, по которому его можно получить
+	Put(r io.Reader) (key string, size int64, err error)
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// File - метаданные загруженного файла, которые хранятся в анкете или вакансии
+type File struct {
+	Key         string    `json:"key"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	ThumbKey    string    `json:"thumb_key,omitempty"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+}
+
+// LocalStore - реализация BlobStore на локальной файловой системе.
+// Ключ - sha256 содержимого, файл лежит в Root/ab/cd/<хэш>,
+// поэтому одинаковые файлы хранятся один раз.
+type LocalStore struct {
+	Root string
+}
+
+// Blobs - хранилище, которое используют обработчики загрузки
+var Blobs BlobStore = &LocalStore{Root: "uploads"}
+
+func NewLocalStore(root string) *LocalStore {
+	return &LocalStore{Root: root}
+}
+
+func (s *LocalStore) path(key string) (string, error) {
+	if len(key) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid blob key")
+	}
+	if _, err := hex.DecodeString(key); err != nil {
+		return "", fmt.Errorf("invalid blob key")
+	}
+	return filepath.Join(s.Root, key[:2], key[2:4], key), nil
+}
+
+func (s *LocalStore) Put(r io.Reader) (string, int64, error) {
+	if err := os.MkdirAll(s.Root, 0755); err != nil {
+		return "", 0, fmt.Errorf("ошибка создания каталога %s: %w", s.Root, err)
+	}
+	// Пишем во временный файл и считаем хэш, затем переносим на место
+	tmp, err := os.CreateTemp(s.Root, "upload-*")
+	if err != nil {
+		return "", 0, fmt.Errorf("ошибка создания временного файла: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("ошибка записи файла: %w", err)
+	}
+
+	key := hex.EncodeToString(h.Sum(nil))
+	dst, _ := s.path(key)
+	if _, err := os.Stat(dst); err == nil {
+		return key, size, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", 0, fmt.Errorf("ошибка создания каталога: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return "", 0, fmt.Errorf("ошибка сохранения файла: %w", err)
+	}
+	return key, size, nil
+}
+
+func (s *LocalStore) Open(key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+func (s *LocalStore) Delete(key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(p)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
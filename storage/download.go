@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"talant/problem"
+	"time"
+)
+
+// SigningKeyEnv - переменная окружения с ключом подписи ссылок на файлы
+const SigningKeyEnv = "FILES_SIGNING_KEY"
+
+// signingKey - ключ подписи ссылок на файлы, задается LoadSigningKey
+var signingKey []byte
+
+// LoadSigningKey читает ключ подписи ссылок из окружения. Ключ не хранится в
+// репозитории: зная его, любой может подписать ссылку на любой файл.
+func LoadSigningKey() error {
+	key := os.Getenv(SigningKeyEnv)
+	if len(key) < 32 {
+		return fmt.Errorf("%s must be set to a random string of at least 32 bytes", SigningKeyEnv)
+	}
+	signingKey = []byte(key)
+	return nil
+}
+
+// DownloadTTL - время жизни подписанной ссылки
+const DownloadTTL = 15 * time.Minute
+
+func sign(key, name string, exp int64) string {
+	if len(signingKey) == 0 {
+		panic("storage: LoadSigningKey was not called")
+	}
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write([]byte(key + "|" + name + "|" + strconv.FormatInt(exp, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignedURL выдает временную ссылку на файл. Права проверяет тот, кто ссылку выдает,
+// /files/{key} проверяет только подпись и срок действия.
+func SignedURL(key, name string, ttl time.Duration) string {
+	exp := time.Now().Add(ttl).Unix()
+	q := url.Values{}
+	q.Set("name", name)
+	q.Set("exp", strconv.FormatInt(exp, 10))
+	q.Set("sig", sign(key, name, exp))
+	return "/files/" + key + "?" + q.Encode()
+}
+
+// RedirectToFile отправляет клиента на подписанную ссылку
+func RedirectToFile(w http.ResponseWriter, r *http.Request, key, name string) {
+	http.Redirect(w, r, SignedURL(key, name, DownloadTTL), http.StatusFound)
+}
+
+// DownloadHandler отдает файл по подписанной ссылке (/files/{key})
+func DownloadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	key := r.PathValue("key")
+	name := r.URL.Query().Get("name")
+	exp, err := strconv.ParseInt(r.URL.Query().Get("exp"), 10, 64)
+	if err != nil {
+		problem.Write(w, http.StatusForbidden, problem.CodeForbidden, "Invalid link")
+		return
+	}
+	if !hmac.Equal([]byte(sign(key, name, exp)), []byte(r.URL.Query().Get("sig"))) {
+		problem.Write(w, http.StatusForbidden, problem.CodeForbidden, "Invalid link")
+		return
+	}
+	if time.Now().Unix() > exp {
+		problem.Write(w, http.StatusForbidden, problem.CodeForbidden, "Link expired")
+		return
+	}
+
+	f, err := Blobs.Open(key)
+	if err != nil {
+		problem.Write(w, http.StatusNotFound, problem.CodeFileNotFound, "File not found")
+		return
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(f, head)
+	head = head[:n]
+	w.Header().Set("Content-Type", http.DetectContentType(head))
+	if name != "" {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": name}))
+	}
+	w.Header().Set("Cache-Control", "private, max-age=900")
+	w.Write(head)
+	io.Copy(w, f)
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Хранилище адресуется по содержимому: один и тот же файл может быть резюме
+// нескольких анкет, логотипом вакансии и вложением в сообщении. Поэтому файл
+// удаляется, только когда ни один владелец на него больше не ссылается.
+
+// Referrer возвращает ключи файлов, на которые ссылаются объекты одного пакета
+type Referrer func() ([]string, error)
+
+var (
+	refMu     sync.Mutex
+	referrers []Referrer
+)
+
+// OnReferences регистрирует источник ссылок на файлы, см. Release
+func OnReferences(fn Referrer) {
+	refMu.Lock()
+	defer refMu.Unlock()
+	referrers = append(referrers, fn)
+}
+
+// Keys возвращает ключи файла и его миниатюры; для nil - пустой список
+func (f *File) Keys() []string {
+	if f == nil {
+		return nil
+	}
+	keys := []string{f.Key}
+	if f.ThumbKey != "" {
+		keys = append(keys, f.ThumbKey)
+	}
+	return keys
+}
+
+// KeysIn находит ключи файлов в JSON объекта, например в содержимом корзины:
+// значения полей key и thumb_key, как их кодирует File
+func KeysIn(data json.RawMessage) []string {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil
+	}
+	var keys []string
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for name, item := range v {
+				if s, ok := item.(string); ok && (name == "key" || name == "thumb_key") {
+					keys = append(keys, s)
+					continue
+				}
+				walk(item)
+			}
+		case []any:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+	walk(v)
+	return keys
+}
+
+// Release удаляет из Blobs файлы keys, на которые не осталось ссылок ни у одного
+// зарегистрированного источника. Если источник не удалось прочитать, ничего не удаляется.
+func Release(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	refMu.Lock()
+	defer refMu.Unlock()
+
+	used := map[string]bool{}
+	for _, fn := range referrers {
+		refs, err := fn()
+		if err != nil {
+			return fmt.Errorf("ошибка подсчета ссылок на файлы: %w", err)
+		}
+		for _, key := range refs {
+			used[key] = true
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range slices.Compact(keys) {
+		if used[key] {
+			continue
+		}
+		if err := Blobs.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// pngWithSize возвращает PNG 1x1, в заголовке которого объявлены размеры w x h
+func pngWithSize(t *testing.T, w, h uint32) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	// Сигнатура (8 байт), длина и тип чанка IHDR (8), затем ширина и высота
+	binary.BigEndian.PutUint32(data[16:], w)
+	binary.BigEndian.PutUint32(data[20:], h)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+		wantW   int
+		wantH   int
+	}{
+		{name: "small image keeps its size", data: pngWithSize(t, 1, 1), wantW: 1, wantH: 1},
+		{name: "declared dimensions over the limit", data: pngWithSize(t, 30000, 30000), wantErr: ErrTooManyPixels},
+		{name: "one huge side", data: pngWithSize(t, MaxPixels+1, 1), wantErr: ErrTooManyPixels},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thumb, err := Thumbnail(bytes.NewReader(tt.data), ThumbSize)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			img, err := jpeg.Decode(bytes.NewReader(thumb))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b := img.Bounds(); b.Dx() != tt.wantW || b.Dy() != tt.wantH {
+				t.Errorf("size = %dx%d, want %dx%d", b.Dx(), b.Dy(), tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestThumbnailScalesDown(t *testing.T) {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1024, 512)))
+	thumb, err := Thumbnail(&buf, ThumbSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(thumb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != ThumbSize || b.Dy() != ThumbSize/2 {
+		t.Errorf("size = %dx%d, want %dx%d", b.Dx(), b.Dy(), ThumbSize, ThumbSize/2)
+	}
+}
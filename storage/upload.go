@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"talant/problem"
+	"time"
+)
+
+// Limits - ограничения на загружаемый файл: размер и допустимые типы,
+// тип определяется по содержимому (http.DetectContentType), а не по заголовку клиента
+type Limits struct {
+	MaxSize int64
+	Types   map[string]bool
+}
+
+var ResumeLimits = Limits{
+	MaxSize: 5 << 20,
+	Types:   map[string]bool{"application/pdf": true},
+}
+
+var ImageLimits = Limits{
+	MaxSize: 2 << 20,
+	Types:   map[string]bool{"image/jpeg": true, "image/png": true},
+}
+
+// AttachmentLimits - вложения в сообщениях: документы и изображения
+var AttachmentLimits = Limits{
+	MaxSize: 5 << 20,
+	Types:   map[string]bool{"application/pdf": true, "image/jpeg": true, "image/png": true},
+}
+
+var (
+	ErrTooLarge    = errors.New("file is too large")
+	ErrBadType     = errors.New("unsupported file type")
+	ErrMissingFile = errors.New("missing file")
+)
+
+// Receive читает файл из multipart-поля field, проверяет лимиты и сохраняет его в Blobs.
+// Для изображений дополнительно сохраняется миниатюра.
+func Receive(w http.ResponseWriter, r *http.Request, field string, lim Limits) (File, error) {
+	// Небольшой запас на заголовки multipart
+	r.Body = http.MaxBytesReader(w, r.Body, lim.MaxSize+64<<10)
+	if err := r.ParseMultipartForm(lim.MaxSize); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return File{}, ErrTooLarge
+		}
+		return File{}, fmt.Errorf("bad multipart form: %w", err)
+	}
+
+	src, header, err := r.FormFile(field)
+	if err != nil {
+		return File{}, ErrMissingFile
+	}
+	defer src.Close()
+
+	if header.Size > lim.MaxSize {
+		return File{}, ErrTooLarge
+	}
+	data, err := io.ReadAll(io.LimitReader(src, lim.MaxSize+1))
+	if err != nil {
+		return File{}, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+	if int64(len(data)) > lim.MaxSize {
+		return File{}, ErrTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	if !lim.Types[contentType] {
+		return File{}, ErrBadType
+	}
+
+	// Миниатюра строится до сохранения, чтобы отклоненное изображение не осталось в Blobs
+	var thumb []byte
+	if contentType == "image/jpeg" || contentType == "image/png" {
+		thumb, err = Thumbnail(bytes.NewReader(data), ThumbSize)
+		if errors.Is(err, ErrTooManyPixels) {
+			return File{}, fmt.Errorf("%w: %v", ErrTooLarge, err)
+		}
+		if err != nil {
+			return File{}, fmt.Errorf("%w: %v", ErrBadType, err)
+		}
+	}
+
+	key, size, err := Blobs.Put(bytes.NewReader(data))
+	if err != nil {
+		return File{}, err
+	}
+	file := File{
+		Key:         key,
+		Name:        filepath.Base(header.Filename),
+		ContentType: contentType,
+		Size:        size,
+		UploadedAt:  time.Now().UTC(),
+	}
+	if thumb != nil {
+		file.ThumbKey, _, err = Blobs.Put(bytes.NewReader(thumb))
+		if err != nil {
+			return File{}, err
+		}
+	}
+	return file, nil
+}
+
+// UploadError отвечает клиенту статусом, соответствующим ошибке Receive
+func UploadError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrTooLarge):
+		problem.Write(w, http.StatusRequestEntityTooLarge, problem.CodeFileTooLarge, "File is too large")
+	case errors.Is(err, ErrBadType):
+		problem.Write(w, http.StatusUnsupportedMediaType, problem.CodeUnsupportedFile, "Unsupported file type")
+	case errors.Is(err, ErrMissingFile):
+		problem.Write(w, http.StatusBadRequest, problem.CodeMissingFile, "Missing file")
+	default:
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error saving file")
+	}
+}
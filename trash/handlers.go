@@ -0,0 +1,51 @@
+package trash
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"talant/auth"
+	"talant/company"
+	"talant/problem"
+)
+
+// ListHandler показывает корзину текущего пользователя (GET /trash): его удаленные
+// вакансии и анкету, а также удаленные вакансии компаний, в которых он состоит
+func ListHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		problem.Write(w, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, err := auth.UserIDFromRequest(r)
+	if err != nil {
+		auth.Error(w, err)
+		return
+	}
+	kind := r.URL.Query().Get("kind")
+	items, err := List(func(it Item) bool {
+		if kind != "" && it.Kind != kind {
+			return false
+		}
+		return it.OwnerID == userID || company.IsMember(it.CompanyID, userID)
+	})
+	if err != nil {
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading trash")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// Error отвечает на ошибку поиска в корзине: 404 или 410 после окончания срока восстановления
+func Error(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		problem.Write(w, http.StatusNotFound, problem.CodeNotFound, "Not found in trash")
+	case errors.Is(err, ErrExpired):
+		problem.Write(w, http.StatusGone, problem.CodeRestoreExpired, "The restore period has expired")
+	default:
+		problem.Write(w, http.StatusInternalServerError, problem.CodeInternal, "Error loading trash")
+	}
+}
@@ -0,0 +1,221 @@
+package trash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"talant/storage"
+	"time"
+)
+
+// Виды объектов, которые удаляются в корзину
+const (
+	KindJob    = "job"
+	KindAnkety = "ankety"
+)
+
+// Retention - сколько удаленный объект можно восстановить, после этого он удаляется навсегда
+const Retention = 30 * 24 * time.Hour
+
+// Item - удаленный объект в корзине. Content - запись объекта в момент удаления,
+// из нее объект восстанавливается с тем же ID.
+type Item struct {
+	Kind     string `json:"kind"`
+	ObjectID string `json:"object_id"`
+	// OwnerID - автор объекта; CompanyID - компания вакансии, ее команда тоже видит корзину
+	OwnerID   string    `json:"owner_id"`
+	CompanyID string    `json:"company_id,omitempty"`
+	Title     string    `json:"title"`
+	DeletedBy string    `json:"deleted_by"`
+	DeletedAt time.Time `json:"deleted_at"`
+	// PurgeAt - окончание срока восстановления
+	PurgeAt time.Time       `json:"purge_at"`
+	Content json.RawMessage `json:"content"`
+}
+
+var (
+	ErrNotFound = errors.New("item not found in trash")
+	ErrExpired  = errors.New("restore period has expired")
+)
+
+var trashFile string = "trash.json"
+
+// mu сериализует изменения trash.json
+var mu sync.Mutex
+
+func Load() ([]Item, error) {
+	data, err := os.ReadFile(trashFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Item{}, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла %s: %w", trashFile, err)
+	}
+	if len(data) == 0 {
+		return []Item{}, nil
+	}
+
+	var items []Item
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("ошибка разбора JSON из файла %s: %w", trashFile, err)
+	}
+	return items, nil
+}
+
+func Save(items []Item) error {
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования в JSON: %w", err)
+	}
+	if err := os.WriteFile(trashFile, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи в файл %s: %w", trashFile, err)
+	}
+	return nil
+}
+
+// Put кладет объект v в корзину. Срок восстановления отсчитывается от item.DeletedAt.
+func Put(item Item, v any) error {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	item.Content = content
+	item.DeletedAt = item.DeletedAt.UTC()
+	item.PurgeAt = item.DeletedAt.Add(Retention)
+
+	mu.Lock()
+	defer mu.Unlock()
+	items, err := Load()
+	if err != nil {
+		return err
+	}
+	return Save(append(items, item))
+}
+
+// Get возвращает объект из корзины, пока его еще можно восстановить
+func Get(kind, objectID string, now time.Time) (*Item, error) {
+	items, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		if items[i].Kind != kind || items[i].ObjectID != objectID {
+			continue
+		}
+		if !now.Before(items[i].PurgeAt) {
+			return nil, ErrExpired
+		}
+		return &items[i], nil
+	}
+	return nil, ErrNotFound
+}
+
+// Remove убирает восстановленный объект из корзины
+func Remove(kind, objectID string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	items, err := Load()
+	if err != nil {
+		return err
+	}
+	kept := items[:0]
+	for _, it := range items {
+		if it.Kind != kind || it.ObjectID != objectID {
+			kept = append(kept, it)
+		}
+	}
+	return Save(kept)
+}
+
+// List возвращает объекты корзины, для которых visible вернула true, начиная с последних удаленных
+func List(visible func(Item) bool) ([]Item, error) {
+	items, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	result := []Item{}
+	for _, it := range items {
+		if visible(it) {
+			result = append(result, it)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].DeletedAt.After(result[j].DeletedAt) })
+	return result, nil
+}
+
+// purgeHooks вызываются для объектов, удаленных навсегда, по виду объекта
+var purgeHooks = map[string][]func(Item) error{}
+
+// OnPurge регистрирует функцию, которая убирает связанные с объектом данные после окончательного удаления
+func OnPurge(kind string, fn func(Item) error) {
+	purgeHooks[kind] = append(purgeHooks[kind], fn)
+}
+
+// Purge навсегда удаляет объекты, срок восстановления которых закончился
+func Purge(now time.Time) error {
+	mu.Lock()
+	items, err := Load()
+	if err != nil {
+		mu.Unlock()
+		return err
+	}
+	var kept, purged []Item
+	for _, it := range items {
+		if now.Before(it.PurgeAt) {
+			kept = append(kept, it)
+		} else {
+			purged = append(purged, it)
+		}
+	}
+	if len(purged) > 0 {
+		if kept == nil {
+			kept = []Item{}
+		}
+		err = Save(kept)
+	}
+	mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, it := range purged {
+		for _, fn := range purgeHooks[it.Kind] {
+			if err := fn(it); err != nil {
+				fmt.Printf("Ошибка очистки после удаления %s %s: %v\n", it.Kind, it.ObjectID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// StartPurger раз в час удаляет из корзины объекты с истекшим сроком восстановления
+func StartPurger() {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			if err := Purge(now); err != nil {
+				fmt.Printf("Ошибка очистки корзины: %v\n", err)
+			}
+		}
+	}()
+}
+
+// FileKeys возвращает ключи файлов объектов в корзине: их еще можно восстановить
+// вместе с вложениями, см. storage.OnReferences
+func FileKeys() ([]string, error) {
+	mu.Lock()
+	items, err := Load()
+	mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, it := range items {
+		keys = append(keys, storage.KeysIn(it.Content)...)
+	}
+	return keys, nil
+}